@@ -0,0 +1,277 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// the digits of pi used to generate the anemoi round constants.
+const (
+	anemoiPi0 = "1415926535897932384626433832795028841971693993751058209749445923078164062862089986280348253421170679"
+	anemoiPi1 = "8214808651328230664709384460955058223172535940812848111745028410270193852110555964462294895493038196"
+)
+
+// anemoiKappa is the constant of the algebraic attacks for each alpha.
+var anemoiKappa = map[int]int64{3: 1, 5: 2, 7: 4, 9: 7, 11: 9}
+
+// AnemoiConst is the constants used in the anemoi permutation,
+// see https://eprint.iacr.org/2022/840.pdf.
+// the state consists of l columns x and l columns y, and each round computes
+// the constant addition, the linear layer and the (open) flystel s-box on each (x_i, y_i).
+type AnemoiConst[E Element[E]] struct {
+	// C and D are the round constants added to x and y, each has Rounds rows and Columns elements.
+	C [][]E
+	D [][]E
+	// Mds is the l x l mds matrix applied to x and the rotated y.
+	Mds      Matrix[E]
+	Alpha    *big.Int
+	AlphaInv *big.Int
+	// Beta is the generator g of the multiplicative group, and Delta is the inverse of g.
+	Beta    E
+	Delta   E
+	Columns int
+	Rounds  int
+}
+
+// GenAnemoiConstants generates the anemoi constants of l columns (the state width is 2l),
+// the mds matrices are only defined for l = 1, 2, 3.
+func GenAnemoiConstants[E Element[E]](columns, securityLevel int) (*AnemoiConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	alpha := sboxAlpha[E]()
+	if _, ok := anemoiKappa[alpha]; !ok {
+		return nil, fmt.Errorf("alpha %d is not supported", alpha)
+	}
+
+	alphaInv := new(big.Int).ModInverse(big.NewInt(int64(alpha)), new(big.Int).Sub(Modulus[E](), big.NewInt(1)))
+	if alphaInv == nil {
+		return nil, fmt.Errorf("x^%d is not a permutation of the field", alpha)
+	}
+
+	g := Info[E]().Generator()
+	delta := NewElement[E]().Inverse(g)
+
+	mds, err := genAnemoiMDS(columns, g)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := calcAnemoiRounds(columns, securityLevel, alpha)
+	c, d := genAnemoiRoundConsts(columns, rounds, alpha, g, delta)
+
+	return &AnemoiConst[E]{
+		C:        c,
+		D:        d,
+		Mds:      mds,
+		Alpha:    big.NewInt(int64(alpha)),
+		AlphaInv: alphaInv,
+		Beta:     g,
+		Delta:    delta,
+		Columns:  columns,
+		Rounds:   rounds,
+	}, nil
+}
+
+// calcAnemoiRounds computes the number of rounds against the algebraic attacks,
+// that is, the smallest r such that binomial(4lr+kappa, 2lr)^2 >= 2^s,
+// then 2 rounds for the second model and min(5, l+1) rounds of security margin are added.
+func calcAnemoiRounds(l, securityLevel, alpha int) int {
+	target := new(big.Int).Lsh(big.NewInt(1), uint(securityLevel))
+
+	r := 0
+	complexity := big.NewInt(0)
+	for complexity.Cmp(target) < 0 {
+		r++
+		complexity.Binomial(int64(4*l*r)+anemoiKappa[alpha], int64(2*l*r))
+		complexity.Mul(complexity, complexity)
+	}
+
+	r += 2
+	if l+1 < 5 {
+		r += l + 1
+	} else {
+		r += 5
+	}
+
+	if r < 8 {
+		r = 8
+	}
+
+	return r
+}
+
+// genAnemoiMDS generates the mds matrix of l columns.
+func genAnemoiMDS[E Element[E]](l int, g E) (Matrix[E], error) {
+	newInt := func(e uint64) E {
+		return NewElement[E]().SetUint64(e)
+	}
+
+	switch l {
+	case 1:
+		return [][]E{{one[E]()}}, nil
+	case 2:
+		// [[1, g], [g, g^2+1]]
+		g2 := NewElement[E]().Square(g)
+		return [][]E{
+			{one[E](), NewElement[E]().Set(g)},
+			{NewElement[E]().Set(g), g2.Add(g2, one[E]())},
+		}, nil
+	case 3:
+		// [[g+1, 1, g+1], [1, 1, g], [g, 1, 1]]
+		g1 := NewElement[E]().Add(g, one[E]())
+		return [][]E{
+			{g1, newInt(1), NewElement[E]().Set(g1)},
+			{newInt(1), newInt(1), NewElement[E]().Set(g)},
+			{NewElement[E]().Set(g), newInt(1), newInt(1)},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("the mds matrix of %d columns is not supported", l)
+}
+
+// genAnemoiRoundConsts generates the round constants from the digits of pi,
+// C_r^i = g*(pi0^r)^2 + (pi0^r + pi1^i)^alpha, D_r^i = g*(pi1^i)^2 + (pi0^r + pi1^i)^alpha + g^-1.
+func genAnemoiRoundConsts[E Element[E]](l, rounds, alpha int, g, delta E) ([][]E, [][]E) {
+	pi0, _ := new(big.Int).SetString(anemoiPi0, 10)
+	pi1, _ := new(big.Int).SetString(anemoiPi1, 10)
+	pi0e := NewElement[E]().SetBigInt(pi0)
+	pi1e := NewElement[E]().SetBigInt(pi1)
+	a := big.NewInt(int64(alpha))
+
+	c := make([][]E, rounds)
+	d := make([][]E, rounds)
+	pi0r := one[E]()
+	for r := 0; r < rounds; r++ {
+		c[r] = make([]E, l)
+		d[r] = make([]E, l)
+
+		pi1i := one[E]()
+		for i := 0; i < l; i++ {
+			sum := NewElement[E]().Add(pi0r, pi1i)
+			powAlpha := NewElement[E]()
+			Exp(powAlpha, sum, a)
+
+			c[r][i] = NewElement[E]().Square(pi0r)
+			c[r][i].Mul(c[r][i], g)
+			c[r][i].Add(c[r][i], powAlpha)
+
+			d[r][i] = NewElement[E]().Square(pi1i)
+			d[r][i].Mul(d[r][i], g)
+			d[r][i].Add(d[r][i], powAlpha)
+			d[r][i].Add(d[r][i], delta)
+
+			pi1i.Mul(pi1i, pi1e)
+		}
+
+		pi0r.Mul(pi0r, pi0e)
+	}
+
+	return c, d
+}
+
+// AnemoiPermute applies the anemoi permutation to the whole state (x_0, ..., x_{l-1}, y_0, ..., y_{l-1}).
+func AnemoiPermute[E Element[E]](input []*big.Int, pdsConsts *AnemoiConst[E]) ([]*big.Int, error) {
+	if len(input) != 2*pdsConsts.Columns {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), 2*pdsConsts.Columns)
+	}
+
+	state := bigToElement[E](input)
+	pdsConsts.permute(state[:pdsConsts.Columns], state[pdsConsts.Columns:])
+
+	return elementToBig(state), nil
+}
+
+// JiveCompress computes the Jive_b compression mode, which compresses the state of width m = 2l
+// to m/b elements, out_j = sum_{i<b} (s_{i*m/b+j} + P(s)_{i*m/b+j}).
+func JiveCompress[E Element[E]](input []*big.Int, b int, pdsConsts *AnemoiConst[E]) ([]*big.Int, error) {
+	m := 2 * pdsConsts.Columns
+	if b < 2 || m%b != 0 {
+		return nil, fmt.Errorf("b %d should be at least 2 and divide the width %d", b, m)
+	}
+
+	if len(input) != m {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), m)
+	}
+
+	state := bigToElement[E](input)
+	pdsConsts.permute(state[:pdsConsts.Columns], state[pdsConsts.Columns:])
+
+	input2 := bigToElement[E](input)
+	out := make([]E, m/b)
+	for j := 0; j < m/b; j++ {
+		out[j] = NewElement[E]()
+		for i := 0; i < b; i++ {
+			out[j].Add(out[j], input2[i*m/b+j])
+			out[j].Add(out[j], state[i*m/b+j])
+		}
+	}
+
+	return elementToBig(out), nil
+}
+
+// anemoiScratch holds the elements used by the rounds, so the rounds do not allocate.
+type anemoiScratch[E Element[E]] struct {
+	// rotated refers to the elements of y rotated by one position.
+	rotated []E
+	mx      []E
+	my      []E
+	tmp     E
+}
+
+// newScratch allocates the scratch elements of the rounds.
+func (c *AnemoiConst[E]) newScratch() *anemoiScratch[E] {
+	return &anemoiScratch[E]{
+		rotated: make([]E, c.Columns),
+		mx:      newElements[E](c.Columns),
+		my:      newElements[E](c.Columns),
+		tmp:     NewElement[E](),
+	}
+}
+
+// permute computes the anemoi permutation in place.
+func (c *AnemoiConst[E]) permute(x, y []E) {
+	s := c.newScratch()
+	for r := 0; r < c.Rounds; r++ {
+		addConsts(x, c.C[r])
+		addConsts(y, c.D[r])
+		c.linearLayer(x, y, s)
+		for i := 0; i < c.Columns; i++ {
+			c.flystel(x[i], y[i], s.tmp)
+		}
+	}
+
+	c.linearLayer(x, y, s)
+}
+
+// linearLayer applies the mds matrix to x and to y rotated by one position,
+// then the pseudo-hadamard transform is applied to each (x_i, y_i).
+func (c *AnemoiConst[E]) linearLayer(x, y []E, s *anemoiScratch[E]) {
+	for i := 0; i < c.Columns; i++ {
+		s.rotated[i] = y[(i+1)%c.Columns]
+	}
+	productMatrix(s.mx, x, c.Mds, s.tmp)
+	productMatrix(s.my, s.rotated, c.Mds, s.tmp)
+
+	for i := 0; i < c.Columns; i++ {
+		y[i].Add(s.my[i], s.mx[i])
+		x[i].Add(s.mx[i], y[i])
+	}
+}
+
+// flystel computes the open flystel s-box in place, tmp is a scratch element:
+// x = x - beta*y^2, y = y - x^(1/alpha), x = x + beta*y^2 + delta.
+func (c *AnemoiConst[E]) flystel(x, y, tmp E) {
+	tmp.Square(y)
+	tmp.Mul(tmp, c.Beta)
+	x.Sub(x, tmp)
+
+	Exp(tmp, x, c.AlphaInv)
+	y.Sub(y, tmp)
+
+	tmp.Square(y)
+	tmp.Mul(tmp, c.Beta)
+	x.Add(x, tmp)
+	x.Add(x, c.Delta)
+}
@@ -0,0 +1,111 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnemoiRounds(t *testing.T) {
+	// the round numbers in the paper (128-bit security).
+	assert.Equal(t, []int{21, 14, 12, 12, 11, 10}, []int{
+		calcAnemoiRounds(1, 128, 3), calcAnemoiRounds(2, 128, 3), calcAnemoiRounds(3, 128, 3),
+		calcAnemoiRounds(4, 128, 3), calcAnemoiRounds(5, 128, 3), calcAnemoiRounds(6, 128, 3),
+	})
+	assert.Equal(t, []int{20, 13, 12, 11, 11, 10}, []int{
+		calcAnemoiRounds(1, 128, 7), calcAnemoiRounds(2, 128, 7), calcAnemoiRounds(3, 128, 7),
+		calcAnemoiRounds(4, 128, 7), calcAnemoiRounds(5, 128, 7), calcAnemoiRounds(6, 128, 7),
+	})
+}
+
+func TestAnemoi(t *testing.T) {
+	// the expected values are computed by a python port of the reference implementation.
+	cases := []struct {
+		columns int
+		rounds  int
+		perm    []string
+		jive    []string
+	}{
+		{
+			columns: 1,
+			rounds:  21,
+			perm: []string{
+				"019ea09bf18332c14411e27d2a654837a188f8b718d13faa824730fa20350684",
+				"68ae6629a63203e1fc2c8ecbfc72eb940a63a0f7ed9bf9d64bec32dec5217cc0",
+			},
+			jive: []string{"6a4d06c597b536a3403e714926d833cbabec99af066d3980ce3363d8e5568345"},
+		},
+		{
+			columns: 2,
+			rounds:  14,
+			perm: []string{
+				"103198778534d584c4e960834939b6549ade65a64facdf0d75742ddc1e1755b6",
+				"504b9b2827c9426bca315935a415895bc787f21b2137cafa259ea66992b416d2",
+				"0abe38e4a44d3ca4ce4fd3470129bfe01e2317f98522899420615d4363b4242a",
+				"674dfdef1d8e6c4600141c587d5ba59b466df8d0fe675474dfc10861fadb0424",
+			},
+			jive: []string{
+				"1aefd15c29821229933933ca4a637634b9017d9fd4cf68a195d58b1f81cb79e2",
+				"43abf1c41bba3169970b9d8617cf56f1ba3846e91fa0c370055faecc8d8f1af9",
+			},
+		},
+		{
+			columns: 3,
+			rounds:  12,
+			perm: []string{
+				"473b3bb8e76a278db06188a63aebcec6fb81154aed96fa172a19aa7bba859760",
+				"56776daad72585727c653f27de243ad22c30a7526dca7d864b936c771b4a5c17",
+				"1cafa7be0f7fb533bc5cda2d9f3337d3a1594414c248bdc8b54cac4cf8ce12d9",
+				"3e3afd4e73cfaa04c0f3d046680e0e03f0131761ea278adb8baea942732ced35",
+				"4dbc18f49ade8b8563f2e81eb50755cd8f9efa909d8449cf95006aa7dfa5a79a",
+				"5b6e6d434ee2b3d0618924f6e942d9d9322870eb16a6669617ee346c1a898261",
+			},
+			jive: []string{
+				"118891b4319c544a3e1b80e4995804c597d688a9d7c028f3b5c853bf2db28497",
+				"3045df4c486693afad1e4f3e8989b89a6811fde00b506b56e093d71ffaf003b5",
+				"04306dae34c4ebbbeaac271c7ed439a77fc410fcd8f0c85fcd3ae0ba13579540",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		cons, err := GenAnemoiConstants[*fr.Element](c.columns, 128)
+		assert.NoError(t, err)
+		assert.Equal(t, c.rounds, cons.Rounds)
+
+		input := make([]*big.Int, 2*c.columns)
+		for i := 0; i < len(input); i++ {
+			input[i] = big.NewInt(int64(i))
+		}
+
+		output, err := AnemoiPermute(input, cons)
+		assert.NoError(t, err)
+		assert.Equal(t, hexToBig(c.perm), output)
+
+		output, err = JiveCompress(input, 2, cons)
+		assert.NoError(t, err)
+		assert.Equal(t, hexToBig(c.jive), output)
+	}
+
+	_, err := GenAnemoiConstants[*fr.Element](4, 128)
+	assert.Error(t, err)
+}
+
+func TestAnemoiAllocs(t *testing.T) {
+	cons, err := GenAnemoiConstants[*fr.Element](2, 128)
+	assert.NoError(t, err)
+	state := bigToElement[*fr.Element]([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3)})
+
+	// only the scratch elements are allocated, the rounds do not allocate.
+	allocs := func(c *AnemoiConst[*fr.Element]) float64 {
+		return testing.AllocsPerRun(10, func() {
+			c.permute(state[:2], state[2:])
+		})
+	}
+
+	one := *cons
+	one.Rounds = 1
+	assert.Equal(t, allocs(&one), allocs(cons))
+}
@@ -0,0 +1,167 @@
+package poseidon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ArkworksConfig is the PoseidonConfig of ark-crypto-primitives in json (e.g. serialized from rust with serde),
+// the field elements are decimal strings (or with a base prefix).
+type ArkworksConfig struct {
+	FullRounds    int        `json:"full_rounds"`
+	PartialRounds int        `json:"partial_rounds"`
+	Alpha         uint64     `json:"alpha"`
+	Ark           [][]string `json:"ark"`
+	Mds           [][]string `json:"mds"`
+	Rate          int        `json:"rate"`
+	Capacity      int        `json:"capacity"`
+}
+
+// ReadArkworksConfig reads the arkworks config in json.
+func ReadArkworksConfig(r io.Reader) (*ArkworksConfig, error) {
+	var config ArkworksConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, fmt.Errorf("decode arkworks config err: %w", err)
+	}
+
+	return &config, nil
+}
+
+// ArkworksConst is the constants used in the arkworks permutation, the state is the capacity elements followed by the rate elements,
+// each round adds the round constants (ark), computes the s-box layer (the partial rounds only on state[0]) and the mds matrix (M*x),
+// the first full_rounds/2 full rounds are followed by the partial rounds and then the remaining full rounds.
+type ArkworksConst[E Element[E]] struct {
+	Mds           Matrix[E]
+	Ark           [][]E
+	Alpha         *big.Int
+	FullRounds    int
+	PartialRounds int
+	Rate          int
+	Capacity      int
+}
+
+// NewArkworksConstants creates the arkworks constants from the config.
+func NewArkworksConstants[E Element[E]](config *ArkworksConfig) (*ArkworksConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	if config.Rate <= 0 || config.Capacity < 0 {
+		return nil, fmt.Errorf("rate %d and capacity %d are invalid", config.Rate, config.Capacity)
+	}
+
+	if config.FullRounds <= 0 || config.PartialRounds < 0 {
+		return nil, fmt.Errorf("round numbers rf=%d, rp=%d are invalid", config.FullRounds, config.PartialRounds)
+	}
+
+	// x^alpha should be a permutation of the field.
+	alpha := new(big.Int).SetUint64(config.Alpha)
+	pMinusOne := new(big.Int).Sub(Modulus[E](), big.NewInt(1))
+	if config.Alpha < 2 || new(big.Int).GCD(nil, nil, alpha, pMinusOne).Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("alpha %d is not a permutation of the field", config.Alpha)
+	}
+
+	width := config.Rate + config.Capacity
+	mds, err := decimalToMatrix[E](config.Mds)
+	if err != nil {
+		return nil, fmt.Errorf("parse mds matrix err: %w", err)
+	}
+
+	if len(mds) != width || !IsSquareMatrix(mds) {
+		return nil, fmt.Errorf("mds matrix should be a %d x %d matrix", width, width)
+	}
+
+	rounds := config.FullRounds + config.PartialRounds
+	if len(config.Ark) != rounds {
+		return nil, fmt.Errorf("round constants length %d is inconsistent, want %d", len(config.Ark), rounds)
+	}
+
+	ark := make([][]E, rounds)
+	for i := 0; i < rounds; i++ {
+		if len(config.Ark[i]) != width {
+			return nil, fmt.Errorf("round constants %d should have %d elements", i, width)
+		}
+
+		if ark[i], err = decimalToElement[E](config.Ark[i]); err != nil {
+			return nil, fmt.Errorf("parse round constants err: %w", err)
+		}
+	}
+
+	return &ArkworksConst[E]{
+		Mds:           mds,
+		Ark:           ark,
+		Alpha:         alpha,
+		FullRounds:    config.FullRounds,
+		PartialRounds: config.PartialRounds,
+		Rate:          config.Rate,
+		Capacity:      config.Capacity,
+	}, nil
+}
+
+// spn returns the network of the arkworks permutation.
+func (c *ArkworksConst[E]) spn() *SPN[E] {
+	linear := func(dst, state []E, _ int, tmp E) {
+		productMatrix(dst, state, c.Mds, tmp)
+	}
+
+	return &SPN[E]{
+		Schedule:  HadesSchedule{FullRounds: c.FullRounds, PartialRounds: c.PartialRounds},
+		SBox:      PowerSBox[E]{Alpha: c.Alpha},
+		Linear:    LinearLayerFunc[E](linear),
+		PreConsts: c.Ark,
+	}
+}
+
+// ArkworksPermute applies the arkworks permutation to the whole state (capacity first).
+func ArkworksPermute[E Element[E]](input []*big.Int, pdsConsts *ArkworksConst[E]) ([]*big.Int, error) {
+	if len(input) != pdsConsts.Rate+pdsConsts.Capacity {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), pdsConsts.Rate+pdsConsts.Capacity)
+	}
+
+	state, err := canonicalToElement[E](input)
+	if err != nil {
+		return nil, err
+	}
+
+	return elementToBig(pdsConsts.spn().Permute(state, nil)), nil
+}
+
+// ArkworksSponge is the PoseidonSponge of ark-crypto-primitives, which absorbs and squeezes the native field elements,
+// the elements are added to the rate elements after the capacity, and the state is permuted before the rate is reused,
+// or when the sponge switches between absorbing and squeezing, see DuplexSpongeMode of arkworks.
+type ArkworksSponge[E Element[E]] struct {
+	sponge *duplexSponge[E]
+}
+
+// NewArkworksSponge creates the sponge of the zero state in the absorbing mode.
+func NewArkworksSponge[E Element[E]](pdsConsts *ArkworksConst[E]) *ArkworksSponge[E] {
+	spn := pdsConsts.spn()
+	permute := func(state []E) {
+		spn.Permute(state, nil)
+	}
+
+	return &ArkworksSponge[E]{sponge: newDuplexSponge(pdsConsts.Capacity, pdsConsts.Rate, permute)}
+}
+
+// Absorb absorbs the field elements, the values should be in the field.
+func (s *ArkworksSponge[E]) Absorb(input []*big.Int) error {
+	elems, err := canonicalToElement[E](input)
+	if err != nil {
+		return err
+	}
+
+	s.sponge.absorb(elems)
+	return nil
+}
+
+// Squeeze squeezes n field elements as squeeze_native_field_elements.
+func (s *ArkworksSponge[E]) Squeeze(n int) []*big.Int {
+	return elementToBig(s.sponge.squeeze(n))
+}
+
+// State returns a copy of the state (capacity first).
+func (s *ArkworksSponge[E]) State() []*big.Int {
+	return elementToBig(s.sponge.state)
+}
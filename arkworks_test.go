@@ -0,0 +1,181 @@
+package poseidon
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	bls12377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// snarkVMArkworksConfig converts snarkvm's constants into the arkworks config,
+// with the transposed mds matrix since arkworks computes M*x.
+func snarkVMArkworksConfig(cons *SnarkVMConst) *ArkworksConfig {
+	width := cons.Rate + 1
+	mds := make([][]string, width)
+	for i := 0; i < width; i++ {
+		mds[i] = make([]string, width)
+		for j := 0; j < width; j++ {
+			mds[i][j] = cons.Poseidon.Mds.m[j][i].String()
+		}
+	}
+
+	rounds := cons.Poseidon.FullRounds + cons.Poseidon.PartialRounds
+	ark := make([][]string, rounds)
+	for r := 0; r < rounds; r++ {
+		ark[r] = make([]string, width)
+		for j := 0; j < width; j++ {
+			ark[r][j] = cons.Poseidon.RoundConsts[r*width+j].String()
+		}
+	}
+
+	return &ArkworksConfig{
+		FullRounds:    cons.Poseidon.FullRounds,
+		PartialRounds: cons.Poseidon.PartialRounds,
+		Alpha:         cons.Poseidon.Alpha.Uint64(),
+		Ark:           ark,
+		Mds:           mds,
+		Rate:          cons.Rate,
+		Capacity:      1,
+	}
+}
+
+func TestArkworksPermute(t *testing.T) {
+	snarkVM, err := GenSnarkVMConstants(4)
+	assert.NoError(t, err)
+	cons, err := NewArkworksConstants[*bls12377fr.Element](snarkVMArkworksConfig(snarkVM))
+	assert.NoError(t, err)
+
+	input := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+	want, err := Permute(input, snarkVM.Poseidon)
+	assert.NoError(t, err)
+	get, err := ArkworksPermute(input, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, want, get)
+
+	_, err = ArkworksPermute(input[:4], cons)
+	assert.Error(t, err)
+}
+
+func TestArkworksSponge(t *testing.T) {
+	snarkVM, err := GenSnarkVMConstants(2)
+	assert.NoError(t, err)
+	cons, err := NewArkworksConstants[*bls12377fr.Element](snarkVMArkworksConfig(snarkVM))
+	assert.NoError(t, err)
+
+	// snarkvm's hash_many is the arkworks sponge of the preimage [domain, len, input].
+	input := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	want, err := SnarkVMHashMany(input, 5, snarkVM)
+	assert.NoError(t, err)
+
+	sponge := NewArkworksSponge(cons)
+	assert.NoError(t, sponge.Absorb([]*big.Int{snarkVM.Domain.BigInt(new(big.Int)), big.NewInt(3)}))
+	assert.NoError(t, sponge.Absorb(input))
+	assert.Equal(t, want[:1], sponge.Squeeze(1))
+	assert.Equal(t, want[1:], sponge.Squeeze(4))
+
+	// the squeezed elements are the rate elements after the capacity.
+	state := sponge.State()
+	assert.Equal(t, state[2:], sponge.Squeeze(1))
+	state, err = ArkworksPermute(state, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, state[1:], sponge.Squeeze(2))
+
+	assert.Error(t, sponge.Absorb([]*big.Int{Modulus[*bls12377fr.Element]()}))
+}
+
+func TestArkworksConfigFile(t *testing.T) {
+	// the config of snarkvm's rate 2 instance in the json layout of PoseidonConfig, which is written by this library
+	// (snarkVMArkworksConfig), not serialized by arkworks, the outputs of arkworks are not included yet.
+	f, err := os.Open("./data/arkworks-bls12-377-rate2.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	config, err := ReadArkworksConfig(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	snarkVM, err := GenSnarkVMConstants(2)
+	assert.NoError(t, err)
+	assert.Equal(t, snarkVMArkworksConfig(snarkVM), config)
+
+	cons, err := NewArkworksConstants[*bls12377fr.Element](config)
+	assert.NoError(t, err)
+
+	// absorb 3 elements, then squeeze 5 elements, which is longer than the rate.
+	sponge := NewArkworksSponge(cons)
+	assert.NoError(t, sponge.Absorb([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}))
+	get := sponge.Squeeze(5)
+
+	state, err := ArkworksPermute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}, cons)
+	assert.NoError(t, err)
+	state[1].Add(state[1], big.NewInt(3)).Mod(state[1], Modulus[*bls12377fr.Element]())
+
+	var want []*big.Int
+	for len(want) < 5 {
+		state, err = ArkworksPermute(state, cons)
+		assert.NoError(t, err)
+		want = append(want, state[1:]...)
+	}
+	assert.Equal(t, want[:5], get)
+}
+
+func TestReadArkworksConfig(t *testing.T) {
+	config, err := ReadArkworksConfig(strings.NewReader(`{
+		"full_rounds": 3,
+		"partial_rounds": 1,
+		"alpha": 17,
+		"ark": [["1", "2"], ["3", "4"], ["5", "6"], ["0x7", "8"]],
+		"mds": [["1", "0"], ["0", "1"]],
+		"rate": 1,
+		"capacity": 1
+	}`))
+	assert.NoError(t, err)
+
+	cons, err := NewArkworksConstants[*bls12377fr.Element](config)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(17), cons.Alpha)
+	assert.True(t, cons.Ark[3][0].Equal(new(bls12377fr.Element).SetUint64(7)))
+
+	// the odd full rounds, the first round is full, and the partial round only applies the s-box to state[0].
+	out, err := ArkworksPermute([]*big.Int{big.NewInt(0), big.NewInt(0)}, cons)
+	assert.NoError(t, err)
+	x, y := new(bls12377fr.Element), new(bls12377fr.Element)
+	for r, c := range cons.Ark {
+		x.Add(x, c[0])
+		y.Add(y, c[1])
+		Exp(x, new(bls12377fr.Element).Set(x), cons.Alpha)
+		if r != 1 {
+			Exp(y, new(bls12377fr.Element).Set(y), cons.Alpha)
+		}
+	}
+	assert.Equal(t, []*big.Int{x.BigInt(new(big.Int)), y.BigInt(new(big.Int))}, out)
+
+	tests := []struct {
+		modify func(c *ArkworksConfig)
+		want   string
+	}{
+		{func(c *ArkworksConfig) { c.Rate = 0 }, "rate"},
+		{func(c *ArkworksConfig) { c.FullRounds = 0 }, "round numbers"},
+		{func(c *ArkworksConfig) { c.Alpha = 3 }, "alpha"},
+		{func(c *ArkworksConfig) { c.PartialRounds = 2 }, "round constants length"},
+		{func(c *ArkworksConfig) { c.Mds = c.Mds[1:] }, "mds matrix"},
+		{func(c *ArkworksConfig) { c.Ark[0] = c.Ark[0][1:] }, "round constants 0"},
+		{func(c *ArkworksConfig) { c.Ark[0][0] = Modulus[*bls12377fr.Element]().String() }, "not in the field"},
+	}
+
+	for _, c := range tests {
+		conf := *config
+		conf.Ark = [][]string{{"1", "2"}, {"3", "4"}, {"5", "6"}, {"7", "8"}}
+		c.modify(&conf)
+		_, err := NewArkworksConstants[*bls12377fr.Element](&conf)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), c.want)
+	}
+
+	_, err = ReadArkworksConfig(strings.NewReader("{"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// GenBarretenbergConstants returns the constants of barretenberg's poseidon2 over BN254 (width 4, rf=8, rp=56, x^5),
+// which is also noir's std::hash::poseidon2, the constants are the ones of the reference implementation,
+// see GenPoseidon2Constants.
+func GenBarretenbergConstants() (*Poseidon2Const[*bn254fr.Element], error) {
+	return GenPoseidon2Constants[*bn254fr.Element](4, nil)
+}
+
+// BarretenbergHash computes noir's poseidon2 hash(input, message_size) by barretenberg's sponge of the rate width-1,
+// the capacity element (the last one) is initialized to message_size*2^64, and only the first message_size elements are absorbed,
+// if message_size is less than the input length (variable length), an extra 1 is absorbed,
+// the input is padded with zeros to a multiple of the rate, and the output is state[0].
+func BarretenbergHash[E Element[E]](input []*big.Int, messageSize int, pdsConsts *Poseidon2Const[E]) (*big.Int, error) {
+	if messageSize < 0 || messageSize > len(input) {
+		return nil, fmt.Errorf("message size %d should be in [0, %d]", messageSize, len(input))
+	}
+
+	elems, err := canonicalToElement[E](input[:messageSize])
+	if err != nil {
+		return nil, err
+	}
+
+	if messageSize != len(input) {
+		elems = append(elems, one[E]())
+	}
+
+	return constantLengthSponge(elems, messageSize, len(pdsConsts.InternalDiag), pdsConsts.permute)
+}
@@ -0,0 +1,73 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarretenbergPermute(t *testing.T) {
+	cons, err := GenBarretenbergConstants()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, cons.FullRounds)
+	assert.Equal(t, 56, cons.PartialRounds)
+
+	// the test vector of barretenberg (poseidon2.test.cpp).
+	out, err := Poseidon2Permute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{
+		felt("01bd538c2ee014ed5141b29e9ae240bf8db3fe5b9a38629a9647cf8d76c01737"),
+		felt("239b62e7db98aa3a2a8f6a0d2fa1709e7a35959aa6c7034814d9daa90cbac662"),
+		felt("04cbb44c61d928ed06808456bf758cbf0c18d1e15a7b6dbc8245fa7515d5e3cb"),
+		felt("2e11c5cff2a22c64d01304b778d78f6998eff1ab73163a35603f54794c30847a"),
+	}, out)
+}
+
+func TestBarretenbergHash(t *testing.T) {
+	cons, err := GenBarretenbergConstants()
+	assert.NoError(t, err)
+
+	zero := big.NewInt(0)
+	a, b, c, d := big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4)
+	iv := func(n int64) *big.Int {
+		return new(big.Int).Lsh(big.NewInt(n), 64)
+	}
+
+	// 3 elements fill the rate.
+	out, err := Poseidon2Permute([]*big.Int{a, b, c, iv(3)}, cons)
+	assert.NoError(t, err)
+	h, err := BarretenbergHash([]*big.Int{a, b, c}, 3, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	// 4 elements, the second chunk is padded with zeros.
+	out, err = Poseidon2Permute([]*big.Int{a, b, c, iv(4)}, cons)
+	assert.NoError(t, err)
+	out[0].Add(out[0], d).Mod(out[0], Modulus[*bn254fr.Element]())
+	out, err = Poseidon2Permute(out, cons)
+	assert.NoError(t, err)
+	h, err = BarretenbergHash([]*big.Int{a, b, c, d}, 4, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	// the variable length absorbs an extra 1 after the message.
+	out, err = Poseidon2Permute([]*big.Int{a, b, big.NewInt(1), iv(2)}, cons)
+	assert.NoError(t, err)
+	h, err = BarretenbergHash([]*big.Int{a, b, c, d}, 2, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	// the empty message permutes the initial state once.
+	out, err = Poseidon2Permute([]*big.Int{zero, zero, zero, zero}, cons)
+	assert.NoError(t, err)
+	h, err = BarretenbergHash(nil, 0, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	_, err = BarretenbergHash([]*big.Int{a}, 2, cons)
+	assert.Error(t, err)
+	_, err = BarretenbergHash([]*big.Int{Modulus[*bn254fr.Element]()}, 1, cons)
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+package poseidon
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldModulus provides the modulus of a BigField, the zero value of the type is used,
+// so the modulus is fixed per Go type: it can be chosen at runtime (e.g. read from a config file)
+// before the first use, but it should not change after that since the field metadata is cached, see Info.
+// RuntimeField is such a type whose modulus is registered by RegisterRuntimeModulus,
+// more fields at runtime need more types:
+//
+//	var modulus *big.Int // loaded at runtime.
+//	type myField struct{}
+//	func (myField) Modulus() *big.Int { return modulus }
+//	cons, _ := GenPoseidonConstants[*BigField[myField]](3)
+type FieldModulus interface {
+	Modulus() *big.Int
+}
+
+// ErrModulusNotRegistered is returned by the constructors when the modulus of a BigField is not given,
+// e.g. RuntimeField is used before RegisterRuntimeModulus.
+var ErrModulusNotRegistered = errors.New("the modulus of the field is not registered")
+
+var (
+	registerLock      sync.Mutex
+	registeredModulus atomic.Pointer[big.Int]
+)
+
+// RuntimeModulus is the modulus of RuntimeField, which is registered by RegisterRuntimeModulus.
+type RuntimeModulus struct{}
+
+// Modulus returns the registered modulus, or nil if no modulus is registered.
+func (RuntimeModulus) Modulus() *big.Int {
+	return registeredModulus.Load()
+}
+
+// RuntimeField is an element of the prime field chosen at runtime, see RegisterRuntimeModulus.
+type RuntimeField = BigField[RuntimeModulus]
+
+// RegisterRuntimeModulus registers the modulus of RuntimeField, which should be an odd prime.
+// the field metadata is cached, so the modulus can be registered only once,
+// registering the same modulus again is a no-op and a different one is an error.
+func RegisterRuntimeModulus(p *big.Int) error {
+	if p.Cmp(big.NewInt(2)) <= 0 || !p.ProbablyPrime(20) {
+		return fmt.Errorf("the modulus %s is not an odd prime", p)
+	}
+
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	if registered := registeredModulus.Load(); registered != nil {
+		if registered.Cmp(p) != 0 {
+			return fmt.Errorf("the runtime modulus is already registered as %s", registered)
+		}
+		return nil
+	}
+
+	registeredModulus.Store(new(big.Int).Set(p))
+	return nil
+}
+
+// BigField is an element of the prime field given by M, which is backed by math/big.
+// it implements Element, and it is much slower than the elements of gnark-crypto,
+// but it works for arbitrary primes and serves as the reference implementation in the differential tests.
+type BigField[M FieldModulus] struct {
+	v big.Int
+}
+
+// modulus returns the modulus of the field, the arithmetic panics if it is not registered,
+// and the constructors return ErrModulusNotRegistered instead, see checkModulus.
+func (z *BigField[M]) modulus() *big.Int {
+	var m M
+	p := m.Modulus()
+	if p == nil {
+		panic(ErrModulusNotRegistered)
+	}
+
+	return p
+}
+
+// hasModulus reports whether the modulus of the field is given, z may be nil.
+func (*BigField[M]) hasModulus() bool {
+	var m M
+	return m.Modulus() != nil
+}
+
+// checkModulus returns ErrModulusNotRegistered if E is a BigField without the modulus,
+// it is called by the constructors before the field metadata is computed.
+func checkModulus[E Element[E]]() error {
+	var z E
+	if f, ok := any(z).(interface{ hasModulus() bool }); ok && !f.hasModulus() {
+		return ErrModulusNotRegistered
+	}
+
+	return nil
+}
+
+// reduce reduces the value into [0, p).
+func (z *BigField[M]) reduce() *BigField[M] {
+	z.v.Mod(&z.v, z.modulus())
+	return z
+}
+
+func (z *BigField[M]) SetUint64(v uint64) *BigField[M] {
+	z.v.SetUint64(v)
+	return z.reduce()
+}
+
+func (z *BigField[M]) SetBigInt(v *big.Int) *BigField[M] {
+	z.v.Set(v)
+	return z.reduce()
+}
+
+// SetBytes interprets e as the bytes of a big-endian unsigned integer, and reduces it modulo p.
+func (z *BigField[M]) SetBytes(e []byte) *BigField[M] {
+	z.v.SetBytes(e)
+	return z.reduce()
+}
+
+// SetString accepts the decimal numbers and the numbers with a base prefix (e.g. 0x).
+func (z *BigField[M]) SetString(s string) (*BigField[M], error) {
+	if _, ok := z.v.SetString(s, 0); !ok {
+		return nil, errors.New("can't parse the number into a field element")
+	}
+
+	return z.reduce(), nil
+}
+
+func (z *BigField[M]) BigInt(res *big.Int) *big.Int {
+	return res.Set(&z.v)
+}
+
+func (z *BigField[M]) String() string {
+	return z.v.String()
+}
+
+func (z *BigField[M]) SetOne() *BigField[M] {
+	z.v.SetUint64(1)
+	return z
+}
+
+func (z *BigField[M]) SetZero() *BigField[M] {
+	z.v.SetUint64(0)
+	return z
+}
+
+func (z *BigField[M]) Set(x *BigField[M]) *BigField[M] {
+	z.v.Set(&x.v)
+	return z
+}
+
+func (z *BigField[M]) Add(x, y *BigField[M]) *BigField[M] {
+	z.v.Add(&x.v, &y.v)
+	if z.v.Cmp(z.modulus()) >= 0 {
+		z.v.Sub(&z.v, z.modulus())
+	}
+
+	return z
+}
+
+func (z *BigField[M]) Sub(x, y *BigField[M]) *BigField[M] {
+	z.v.Sub(&x.v, &y.v)
+	if z.v.Sign() < 0 {
+		z.v.Add(&z.v, z.modulus())
+	}
+
+	return z
+}
+
+func (z *BigField[M]) Mul(x, y *BigField[M]) *BigField[M] {
+	z.v.Mul(&x.v, &y.v)
+	return z.reduce()
+}
+
+func (z *BigField[M]) Square(x *BigField[M]) *BigField[M] {
+	return z.Mul(x, x)
+}
+
+// Inverse computes the inverse of x, the inverse of zero is zero.
+func (z *BigField[M]) Inverse(x *BigField[M]) *BigField[M] {
+	if x.v.Sign() == 0 {
+		return z.SetZero()
+	}
+
+	z.v.ModInverse(&x.v, z.modulus())
+	return z
+}
+
+func (z *BigField[M]) Neg(x *BigField[M]) *BigField[M] {
+	if x.v.Sign() == 0 {
+		return z.SetZero()
+	}
+
+	z.v.Sub(z.modulus(), &x.v)
+	return z
+}
+
+func (z *BigField[M]) Equal(x *BigField[M]) bool {
+	return z.v.Cmp(&x.v) == 0
+}
+
+func (z *BigField[M]) IsZero() bool {
+	return z.v.Sign() == 0
+}
+
+// Marshal returns the big-endian bytes of the value, padded to the byte length of the modulus.
+func (z *BigField[M]) Marshal() []byte {
+	return z.v.FillBytes(make([]byte, (z.modulus().BitLen()+7)/8))
+}
+
+func (z *BigField[M]) Cmp(x *BigField[M]) int {
+	return z.v.Cmp(&x.v)
+}
@@ -0,0 +1,128 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// runtimeModulus and runtimeSmallModulus are set before the first use of runtimeField and runtimeSmallField,
+// the modulus of a type should not change after the first use since the field metadata is cached.
+var runtimeModulus, runtimeSmallModulus *big.Int
+
+type runtimeField struct{}
+
+func (runtimeField) Modulus() *big.Int { return runtimeModulus }
+
+type runtimeSmallField struct{}
+
+func (runtimeSmallField) Modulus() *big.Int { return runtimeSmallModulus }
+
+func TestBigFieldDifferential(t *testing.T) {
+	runtimeModulus = fr.Modulus()
+	assert.Equal(t, fr.Modulus(), Modulus[*BigField[runtimeField]]())
+
+	for _, width := range []int{3, 5} {
+		want, err := GenPoseidonConstants[*fr.Element](width)
+		assert.NoError(t, err)
+		cons, err := GenPoseidonConstants[*BigField[runtimeField]](width)
+		assert.NoError(t, err)
+		assert.Equal(t, elementToBig(want.RoundConsts), elementToBig(cons.RoundConsts))
+
+		input := make([]*big.Int, width-1)
+		for i := 0; i < len(input); i++ {
+			input[i] = big.NewInt(int64(i))
+		}
+
+		for _, mode := range []HashMode{OptimizedStatic, OptimizedDynamic, Correct} {
+			h1, err := Hash(input, want, mode)
+			assert.NoError(t, err)
+			h2, err := Hash(input, cons, mode)
+			assert.NoError(t, err)
+			assert.Equal(t, h1, h2)
+		}
+	}
+}
+
+func TestBigFieldSmallPrime(t *testing.T) {
+	runtimeSmallModulus = big.NewInt(int64(BabyBearModulus))
+
+	want, err := GenPoseidonConstants[*BabyBear](16)
+	assert.NoError(t, err)
+	cons, err := GenPoseidonConstants[*BigField[runtimeSmallField]](16)
+	assert.NoError(t, err)
+
+	input := make([]*big.Int, 15)
+	for i := 0; i < len(input); i++ {
+		input[i] = big.NewInt(int64(i))
+	}
+
+	h1, err := Hash(input, want, OptimizedStatic)
+	assert.NoError(t, err)
+	h2, err := Hash(input, cons, OptimizedStatic)
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	// the matrix inversion works unchanged.
+	inv, err := Invert(cons.Mds.m)
+	assert.NoError(t, err)
+	assert.True(t, IsEqual(inv, cons.Mds.mInv))
+
+	// the inverse of zero is zero, and the string is reduced.
+	assert.Equal(t, 0, zero[*BigField[runtimeSmallField]]().Cmp(NewElement[*BigField[runtimeSmallField]]().Inverse(zero[*BigField[runtimeSmallField]]())))
+	x, err := NewElement[*BigField[runtimeSmallField]]().SetString("0x78000002")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, x.Cmp(one[*BigField[runtimeSmallField]]()))
+}
+
+func TestRegisterRuntimeModulus(t *testing.T) {
+	assert.ErrorContains(t, RegisterRuntimeModulus(big.NewInt(2)), "odd prime")
+	assert.ErrorContains(t, RegisterRuntimeModulus(big.NewInt(15)), "odd prime")
+
+	p := big.NewInt(int64(BabyBearModulus))
+	assert.NoError(t, RegisterRuntimeModulus(p))
+	assert.NoError(t, RegisterRuntimeModulus(p))
+	assert.ErrorContains(t, RegisterRuntimeModulus(fr.Modulus()), "already registered")
+	assert.Equal(t, p, Modulus[*RuntimeField]())
+
+	want, err := GenPoseidonConstants[*BabyBear](16)
+	assert.NoError(t, err)
+	cons, err := GenPoseidonConstants[*RuntimeField](16)
+	assert.NoError(t, err)
+
+	input := make([]*big.Int, 15)
+	for i := 0; i < len(input); i++ {
+		input[i] = big.NewInt(int64(i))
+	}
+
+	h1, err := Hash(input, want, OptimizedStatic)
+	assert.NoError(t, err)
+	h2, err := Hash(input, cons, OptimizedStatic)
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+}
+
+// unregisteredField is a field whose modulus is never given.
+type unregisteredField struct{}
+
+func (unregisteredField) Modulus() *big.Int { return nil }
+
+func TestUnregisteredModulus(t *testing.T) {
+	_, err := GenPoseidonConstants[*BigField[unregisteredField]](3)
+	assert.ErrorIs(t, err, ErrModulusNotRegistered)
+	_, err = GenPoseidon2Constants[*BigField[unregisteredField]](3, nil)
+	assert.ErrorIs(t, err, ErrModulusNotRegistered)
+	_, err = GenRescuePrimeConstants[*BigField[unregisteredField]](3, 1, 128)
+	assert.ErrorIs(t, err, ErrModulusNotRegistered)
+	_, err = GenGriffinConstants[*BigField[unregisteredField]](3, 128)
+	assert.ErrorIs(t, err, ErrModulusNotRegistered)
+	_, err = LoadStandardConstants[*BigField[unregisteredField]](3)
+	assert.ErrorIs(t, err, ErrModulusNotRegistered)
+
+	// the arithmetic still panics.
+	assert.PanicsWithError(t, ErrModulusNotRegistered.Error(), func() {
+		NewElement[*BigField[unregisteredField]]().SetUint64(1)
+	})
+}
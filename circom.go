@@ -0,0 +1,55 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// circomPartialRounds are the partial rounds of circomlib for the widths 2 to 17, the full rounds are 8.
+var circomPartialRounds = []int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+// GenCircomConstants generates the constants of circomlib's poseidon over BN254 for the widths 2 to 17,
+// which are generated by the reference script, see GenReferenceConstants.
+func GenCircomConstants(width int) (*PoseidonConst[*bn254fr.Element], error) {
+	if width < 2 || width > len(circomPartialRounds)+1 {
+		return nil, fmt.Errorf("width %d should be in [2, %d]", width, len(circomPartialRounds)+1)
+	}
+
+	return GenReferenceConstants[*bn254fr.Element](width, 8, circomPartialRounds[width-2])
+}
+
+// CircomHash computes the poseidon hash as circomlib's Poseidon template,
+// the initial state is zero (no domain tag), and the output is state[0].
+func CircomHash[E Element[E]](input []*big.Int, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
+	out, err := CircomHashEx(input, big.NewInt(0), 1, pdsConsts)
+	if err != nil {
+		return nil, err
+	}
+
+	return out[0], nil
+}
+
+// CircomHashEx computes the poseidon hash as circomlib's PoseidonEx template,
+// the state is (initialState, input...), and the first nOuts elements of the output state are returned.
+func CircomHashEx[E Element[E]](input []*big.Int, initialState *big.Int, nOuts int, pdsConsts *PoseidonConst[E]) ([]*big.Int, error) {
+	width := row(pdsConsts.Mds.m)
+	if len(input)+1 != width {
+		return nil, fmt.Errorf("input length %d is inconsistent with the width %d", len(input), width)
+	}
+
+	if nOuts < 1 || nOuts > width {
+		return nil, fmt.Errorf("outputs %d should be in [1, %d]", nOuts, width)
+	}
+
+	// circomlib rejects the values which are not in the field.
+	state, err := canonicalToElement[E](append([]*big.Int{initialState}, input...))
+	if err != nil {
+		return nil, err
+	}
+
+	out := pdsConsts.staticSPN().Permute(state, nil)
+
+	return elementToBig(out[:nOuts]), nil
+}
@@ -0,0 +1,128 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircomConstants(t *testing.T) {
+	// the first and the last compressed round constants and M[0][t-1] of circomlib (poseidon_constants_opt).
+	tests := []struct {
+		width            int
+		first, last, mds string
+	}{
+		{2, "9c46e9ec68e9bd4fe1faaba294cba38a71aa177534cdd1b6c7dc0dbd0abd7a7", "e3eca007699dd0f852eb22da642e495f67c988dd5bf0137676b16a31eab4667", "cc57cdbb08507d62bf67a4493cc262fb6c09d557013fff1f573f431221f8ff9"},
+		{3, "ee9a592ba9a9518d05986d656f40c2114c4993c11bb29938d21d47304cd8e6e", "2e211b39a023031a22acc1a1f5f3bb6d8c2666a6379d9d2c40cc8f78b7bd9abe", "143021ec686a3f330d5f9e654638065ce6cd79e28c5b3753326244ee65a1b1a7"},
+		{4, "19b849f69450b06848da1d39bd5e4a4302bb86744edc26238b0878e269ed23e5", "42b6ffe687bc23a2bf6b73317286a543c60ed122fc225aae742c3a1c2dd3a1d", "2f545e578202c9732488540e41f783b68ff0613fd79375f8ba8b3d30958e7677"},
+		{5, "eb544fee2815dda7f53e29ccac98ed7d889bb4ebd47c3864f3c2bd81a6da891", "29a7ff0720e170c0e67efde72795328fecef66daada5f0e2ca858a8c6135fd48", "2a20e3a4a0e57d92f97c9d6186c6c3ea7c5e55c20146259be2f78c2ccc2e3595"},
+		{6, "1448614598e00f98e7ae7dea45fbd83bd968653ef8390cde2e86b706ad40c651", "2507bd3788f57cefee754d3ff5a92980dcf062ae4a3fad93ec0059eb2fe545ec", "24be510095436206dd0abd0b0cbb95c883ab304aa52598b1a69306ec981a688d"},
+		{7, "2197703fceb4cbf07c6dbf46c4ad93e7d14e554db66d09102ff84824743fe4e7", "2c17bd7af463d52d3fd7e8723d11078369561ca3c4ece48f1298adabe7c822e0", "548541724f64e20128260899abe8fbcdff184a1957a9385fb715923bf0fc79d"},
+		{8, "123992df3b9daa65139ec13fbb52f7d348e134333684c1596feb0e8d8c3ad596", "191fe12396efc23ef8865e32908d76c09ab0fe52a4665554020b7ba659386297", "fc9cc95222f92715cf7476010225e14cc38f314071e50e0f3bc9ec674a8aacc"},
+		{9, "2088ce9534577bf38be7bc457f2756d558d66e0c07b9cc001a580bd42cda0e77", "15c7fb9fcf8f1a92cf0c677fe58b79065a5a502d778ac6967c022f6f31132405", "1c5fd9060d4e0d999264429a5004c165570bd1675b09f977708b1ca48e616388"},
+		{10, "e1962c232fd0a6bb54ad8962a82b9838cfef19d290a55fc49d6debd061cd2f6", "12dbd5616e93afff056e7402afb361f5753ed668d6ded08cc8be3febe58ae3d1", "23b02d00fe2634889673a7d04736fe15b9f62652b1c0626a19af8d6085d70822"},
+		{11, "752af3c6fdccaf3868276685f0a69b9749e1706a82917b64ec2ef847f804559", "2179ceef0feee3b0b03c9658e97b6342f25b208d7ed8e526150fc3b559128c5f", "1951523a4270c54403dfe3218fd3d4ccefa43114f1b19fb2c2821a4374718b9d"},
+		{12, "1512df0135b6692589f071140a60749cf775c642b300da2fb4ad5c6e23ad4e5f", "2f730a0fbf24810d49065521296809b50c5e96a6bdc4e3fe44d9d4517a259728", "83d832adf9bb4a25f686abeda9f16ebf53263dcf9ef4311781d09920d094ead"},
+		{13, "1373c771cdf15121a224f330d84b6688ef9fe0038a3bc26a28e3196578a0000d", "1ba2ce3d6e352d0643b2d3c5c7c023b6d1777f246278cfe6e1654e9e5413b535", "227cb43b9818924f2a2588964a0ad7ddfa3df62284b4b3b10199a501ed970622"},
+		{14, "304c29aeb6f1873847879576d30f1f6e8a3ce41082c15c7632df920d6db5164d", "30250c77a64030f322e0fce46eb9783655a93645784b5219c09eb0ccf3fe88a8", "4646253380f4bf642972ae81977779454aa0833fda36b1822cb9c09719e3dd1"},
+		{15, "148d9e4542066b125da6d69ff3fb676ebd27e6a38a1ff4281bd639c97af6ffd5", "1f11f75c7d0ac841001b3123c045526e98c203d1a6caa18d635f2f2bfa735738", "225e00a0da5a7f7496b70d0923a7cf7ff332bff06958cc0f3fad439874f6024d"},
+		{16, "11e27da7b7ef964948a332974d07cceea778bc33aedbcd09de222fa22ad0b101", "7b5719a1e09b96a5ce2c130b9d3ec76002d81bd6a8b46ad27274036bb363bba", "2964901a2b42e9aea1a6593662aeb9e12077a287434bda4ec2012e36a19dc95c"},
+		{17, "2fb583762b37592c6c5a95eb1d06694b6c6f9dc4f1ad4862dd8f5e67cb7a3f5c", "e7119275a25b2b8a4a6aca534650e84ec72b490a41384d44975ed8c80a2a28a", "14c09d155c5d428198c234b553da338f227cbc12b0e42f2b9ce71563ee1495c0"},
+	}
+
+	for _, c := range tests {
+		cons, err := GenCircomConstants(c.width)
+		assert.NoError(t, err)
+		assert.Equal(t, hexToElement[*bn254fr.Element]([]string{c.first})[0], cons.CompRoundConsts[0])
+		assert.Equal(t, hexToElement[*bn254fr.Element]([]string{c.last})[0], cons.CompRoundConsts[len(cons.CompRoundConsts)-1])
+		assert.Equal(t, hexToElement[*bn254fr.Element]([]string{c.mds})[0], cons.Mds.m[0][c.width-1])
+	}
+
+	_, err := GenCircomConstants(18)
+	assert.Error(t, err)
+}
+
+func circomInput(n ...int64) []*big.Int {
+	res := make([]*big.Int, len(n))
+	for i := 0; i < len(n); i++ {
+		res[i] = big.NewInt(n[i])
+	}
+
+	return res
+}
+
+func TestCircomHash(t *testing.T) {
+	// the test vectors of circomlibjs.
+	tests := []struct {
+		input []*big.Int
+		want  string
+	}{
+		{circomInput(1), "18586133768512220936620570745912940619677854269274689475585506675881198879027"},
+		{circomInput(1, 2), "7853200120776062878684798364095072458815029376092732009249414926327459813530"},
+		{circomInput(1, 2, 0, 0, 0), "1018317224307729531995786483840663576608797660851238720571059489595066344487"},
+		{circomInput(1, 2, 0, 0, 0, 0), "15336558801450556532856248569924170992202208561737609669134139141992924267169"},
+		{circomInput(3, 4, 0, 0, 0), "5811595552068139067952687508729883632420015185677766880877743348592482390548"},
+		{circomInput(3, 4, 0, 0, 0, 0), "12263118664590987767234828103155242843640892839966517009184493198782366909018"},
+		{circomInput(1, 2, 3, 4, 5, 6), "20400040500897583745843009878988256314335038853985262692600694741116813247201"},
+		{circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14), "8354478399926161176778659061636406690034081872658507739535256090879947077494"},
+		{circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 0, 0, 0, 0), "5540388656744764564518487011617040650780060800286365721923524861648744699539"},
+		{circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 0, 0, 0, 0, 0, 0), "11882816200654282475720830292386643970958445617880627439994635298904836126497"},
+		{circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16), "9989051620750914585850546081941653841776809718687451684622678807385399211877"},
+	}
+
+	for _, c := range tests {
+		cons, err := GenCircomConstants(len(c.input) + 1)
+		assert.NoError(t, err)
+		h, err := CircomHash(c.input, cons)
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, h.String())
+	}
+}
+
+func TestCircomHashEx(t *testing.T) {
+	// PoseidonEx with the initial state 7.
+	cons, err := GenCircomConstants(5)
+	assert.NoError(t, err)
+	out, err := CircomHashEx(circomInput(1, 2, 3, 4), big.NewInt(7), 4, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(out))
+	assert.Equal(t, "1569211601569591254857354699102545060324851338714426496554851741114291465006", out[0].String())
+
+	// all outputs of the width 17 with the initial state 17.
+	cons, err = GenCircomConstants(17)
+	assert.NoError(t, err)
+	out, err = CircomHashEx(circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16), big.NewInt(17), 16, cons)
+	assert.NoError(t, err)
+
+	want := []string{
+		"7865037705064445207187340054656830232157001572238023180016026650118519857086",
+		"9292383997006336854008325030029058442489692927472584277596649832441082093099",
+		"21700625464938935909463291795162623951575229166945244593449711331894544619498",
+		"1749964961100464837642084889776091157070407086051097880220367435814831060919",
+		"14926884742736943105557530036865339747160219875259470496706517357951967126770",
+		"2039691552066237153485547245250552033884196017621501609319319339955236135906",
+		"15632370980418377873678240526508190824831030254352022226082241110936555130543",
+		"12415717486933552680955550946925876656737401305417786097937904386023163034597",
+		"19518791782429957526810500613963817986723905805167983704284231822835104039583",
+		"3946357499058599914103088366834769377007694643795968939540941315474973940815",
+		"5618081863604788554613937982328324792980580854673130938690864738082655170455",
+		"9119013501536010391475078939286676645280972023937320238963975266387024327421",
+		"8377736769906336164136520530350338558030826788688113957410934156526990238336",
+		"15295058061474937220002017533551270394267030149562824985607747654793981405060",
+		"3767094797637425204201844274463024412131937665868967358407323347727519975724",
+		"11046361685833871233801453306150294246339755171874771935347992312124050338976",
+	}
+	for i := 0; i < len(want); i++ {
+		assert.Equal(t, want[i], out[i].String())
+	}
+
+	// the values not in the field and the invalid outputs are rejected.
+	_, err = CircomHashEx(circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16), bn254fr.Modulus(), 1, cons)
+	assert.Error(t, err)
+	_, err = CircomHashEx(circomInput(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16), big.NewInt(0), 18, cons)
+	assert.Error(t, err)
+	_, err = CircomHash(circomInput(1, 2), cons)
+	assert.Error(t, err)
+}
@@ -21,7 +21,12 @@ type Element[E any] interface {
 	Mul(E, E) E
 	Add(E, E) E
 	Sub(E, E) E
+	Neg(E) E
 	Cmp(x E) int
+	Equal(E) bool
+	IsZero() bool
+	// Marshal returns the big-endian bytes of the canonical value, padded to the byte length of the modulus.
+	Marshal() []byte
 }
 
 func NewElement[E Element[E]]() E {
@@ -30,9 +35,14 @@ func NewElement[E Element[E]]() E {
 	return val.Interface().(E)
 }
 
-func isNil[E Element[E]](t E) bool {
-	v := reflect.ValueOf(t)
-	return v.IsNil()
+// newElements allocates n zero elements.
+func newElements[E Element[E]](n int) []E {
+	res := make([]E, n)
+	for i := 0; i < n; i++ {
+		res[i] = NewElement[E]()
+	}
+
+	return res
 }
 
 func zero[E Element[E]]() E {
@@ -43,25 +53,24 @@ func one[E Element[E]]() E {
 	return NewElement[E]().SetOne()
 }
 
+// Modulus returns a copy of the cached modulus of the field.
 func Modulus[E Element[E]]() *big.Int {
-	e := NewElement[E]().SetZero()
-	e.Sub(e, NewElement[E]().SetOne())
-	b := e.BigInt(new(big.Int))
-	return b.Add(b, big.NewInt(1))
+	return new(big.Int).Set(Info[E]().Modulus)
 }
 
 func Bits[E Element[E]]() int {
-	return Modulus[E]().BitLen()
+	return Info[E]().Bits
 }
 
 func Bytes[E Element[E]]() int {
-	return (Bits[E]() + 7) / 8
+	return Info[E]().Bytes
 }
 
 // Exp is a copy of gnark-crypto's implementation, but takes a pointer argument
 func Exp[E Element[E]](z, x E, k *big.Int) {
 	if k.IsUint64() && k.Uint64() == 0 {
 		z.SetOne()
+		return
 	}
 
 	e := k
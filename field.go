@@ -0,0 +1,207 @@
+package poseidon
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// FieldInfo is the metadata of the field, which is computed once for each element type, see Info.
+type FieldInfo[E Element[E]] struct {
+	Modulus *big.Int
+	// Bits and Bytes are the bit length and the byte length of the modulus.
+	Bits  int
+	Bytes int
+	// TwoAdicity is the largest s such that 2^s divides p-1.
+	TwoAdicity int
+
+	generator     E
+	generatorOnce sync.Once
+}
+
+// Generator returns the smallest generator of the multiplicative group of the field,
+// it is computed on the first call since p-1 has to be factorized.
+func (f *FieldInfo[E]) Generator() E {
+	f.generatorOnce.Do(func() {
+		f.generator = primitiveElement[E]()
+	})
+
+	return NewElement[E]().Set(f.generator)
+}
+
+// fieldInfos caches the field metadata, the key is the nil pointer (*E)(nil), which is distinct for each element type.
+var fieldInfos sync.Map
+
+// Info returns the cached metadata of the field,
+// note that the modulus of a BigField should not change after the first use.
+func Info[E Element[E]]() *FieldInfo[E] {
+	key := any((*E)(nil))
+	if info, ok := fieldInfos.Load(key); ok {
+		return info.(*FieldInfo[E])
+	}
+
+	// p = -1 + 1
+	p := NewElement[E]().SetOne()
+	p.Neg(p)
+	modulus := p.BigInt(new(big.Int))
+	modulus.Add(modulus, big.NewInt(1))
+
+	pMinusOne := new(big.Int).Sub(modulus, big.NewInt(1))
+	info := &FieldInfo[E]{
+		Modulus:    modulus,
+		Bits:       modulus.BitLen(),
+		Bytes:      (modulus.BitLen() + 7) / 8,
+		TwoAdicity: int(pMinusOne.TrailingZeroBits()),
+	}
+
+	actual, _ := fieldInfos.LoadOrStore(key, info)
+	return actual.(*FieldInfo[E])
+}
+
+// knownGenerators are the smallest generators of the fields where p-1 is slow to factorize,
+// the key is the modulus in hex.
+var knownGenerators = map[string]uint64{
+	// BN254, p-1 has a 51-bit prime factor.
+	"30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001": 5,
+	// the pasta fields, 2, 3 and 4 are squares, and 5 is the generator of pasta_curves.
+	"40000000000000000000000000000000224698fc094cf91b992d30ed00000001": 5,
+	"40000000000000000000000000000000224698fc0994a8dd8c46eb2100000001": 5,
+}
+
+// primitiveElement returns the smallest generator of the multiplicative group of the field,
+// that is, the smallest g such that g^((p-1)/q) != 1 for every prime factor q of p-1.
+func primitiveElement[E Element[E]]() E {
+	if g, ok := knownGenerators[Modulus[E]().Text(16)]; ok {
+		return NewElement[E]().SetUint64(g)
+	}
+
+	pMinusOne := new(big.Int).Sub(Modulus[E](), big.NewInt(1))
+	factors := primeFactors(pMinusOne)
+
+	exps := make([]*big.Int, len(factors))
+	for i, q := range factors {
+		exps[i] = new(big.Int).Div(pMinusOne, q)
+	}
+
+	for g := uint64(2); ; g++ {
+		x := NewElement[E]().SetUint64(g)
+		isGenerator := true
+		for _, k := range exps {
+			z := NewElement[E]()
+			Exp(z, x, k)
+			if z.Equal(one[E]()) {
+				isGenerator = false
+				break
+			}
+		}
+
+		if isGenerator {
+			return x
+		}
+	}
+}
+
+// primeFactors returns the distinct prime factors of n in ascending order,
+// the small factors are removed by trial division, and the others are found by pollard's rho.
+func primeFactors(n *big.Int) []*big.Int {
+	n = new(big.Int).Set(n)
+	var factors []*big.Int
+
+	// trial division.
+	for q := int64(2); q < 1<<16; q++ {
+		bq := big.NewInt(q)
+		if new(big.Int).Mod(n, bq).Sign() != 0 {
+			continue
+		}
+
+		factors = append(factors, bq)
+		for new(big.Int).Mod(n, bq).Sign() == 0 {
+			n.Div(n, bq)
+		}
+	}
+
+	// the remaining composite factors.
+	stack := []*big.Int{n}
+	for len(stack) > 0 {
+		m := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch {
+		case m.Cmp(big.NewInt(1)) == 0:
+			continue
+		case m.ProbablyPrime(20):
+			if !containsBig(factors, m) {
+				factors = append(factors, m)
+			}
+			continue
+		}
+
+		d := pollardRho(m)
+		stack = append(stack, d, new(big.Int).Div(m, d))
+	}
+
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].Cmp(factors[j]) < 0
+	})
+
+	return factors
+}
+
+// pollardRho returns a non-trivial factor of the composite n by brent's variant of pollard's rho.
+func pollardRho(n *big.Int) *big.Int {
+	one := big.NewInt(1)
+	for c := int64(1); ; c++ {
+		bc := big.NewInt(c)
+		f := func(x *big.Int) *big.Int {
+			x.Mul(x, x)
+			x.Add(x, bc)
+			return x.Mod(x, n)
+		}
+
+		y, x, ys := big.NewInt(2), new(big.Int), new(big.Int)
+		q, d, diff := big.NewInt(1), big.NewInt(1), new(big.Int)
+		const batch = 128
+		for r := 1; d.Cmp(one) == 0; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				f(y)
+			}
+
+			for k := 0; k < r && d.Cmp(one) == 0; k += batch {
+				ys.Set(y)
+				for i := 0; i < batch && i < r-k; i++ {
+					f(y)
+					q.Mul(q, diff.Sub(x, y).Abs(diff))
+					q.Mod(q, n)
+				}
+				d.GCD(nil, nil, q, n)
+			}
+		}
+
+		if d.Cmp(n) == 0 {
+			// backtrack one step at a time.
+			for {
+				f(ys)
+				d.GCD(nil, nil, diff.Sub(x, ys).Abs(diff), n)
+				if d.Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+
+		if d.Cmp(n) != 0 {
+			return d
+		}
+	}
+}
+
+// containsBig determines if the big integer is in the array.
+func containsBig(a []*big.Int, b *big.Int) bool {
+	for _, x := range a {
+		if x.Cmp(b) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,357 @@
+package poseidon
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// the 31-bit fields used by the STARK stacks.
+const (
+	// BabyBearModulus is 15*2^27 + 1.
+	BabyBearModulus uint32 = 0x78000001
+	// KoalaBearModulus is 2^31 - 2^24 + 1.
+	KoalaBearModulus uint32 = 0x7f000001
+	// Mersenne31Modulus is 2^31 - 1.
+	Mersenne31Modulus uint32 = 0x7fffffff
+)
+
+// BabyBear is an element of the BabyBear field in the montgomery form.
+type BabyBear = mont31[babyBearParams]
+
+// KoalaBear is an element of the KoalaBear field in the montgomery form.
+type KoalaBear = mont31[koalaBearParams]
+
+// mont31Params is the modulus of a 31-bit montgomery field.
+type mont31Params interface {
+	field() *mont31Field
+}
+
+// mont31Field is the modulus p and the montgomery constants, where R = 2^32,
+// mu = -p^-1 mod R and r2 = R^2 mod p.
+type mont31Field struct {
+	p  uint32
+	mu uint32
+	r2 uint32
+}
+
+func newMont31Field(p uint32) *mont31Field {
+	// p^-1 mod 2^32 by newton's iteration, each step doubles the number of correct bits.
+	inv := p
+	for i := 0; i < 4; i++ {
+		inv *= 2 - p*inv
+	}
+
+	r := (uint64(1) << 32) % uint64(p)
+	return &mont31Field{p: p, mu: -inv, r2: uint32(r * r % uint64(p))}
+}
+
+var (
+	babyBearField  = newMont31Field(BabyBearModulus)
+	koalaBearField = newMont31Field(KoalaBearModulus)
+)
+
+type babyBearParams struct{}
+
+func (babyBearParams) field() *mont31Field { return babyBearField }
+
+type koalaBearParams struct{}
+
+func (koalaBearParams) field() *mont31Field { return koalaBearField }
+
+// reduce computes t*R^-1 mod p for t < p*R.
+func (f *mont31Field) reduce(t uint64) uint32 {
+	m := uint32(t) * f.mu
+	u := uint32((t + uint64(m)*uint64(f.p)) >> 32)
+	if u >= f.p {
+		u -= f.p
+	}
+
+	return u
+}
+
+// mont31 is an element of a 31-bit field in the montgomery form, it implements Element.
+type mont31[P mont31Params] struct {
+	v uint32
+}
+
+func (z *mont31[P]) field() *mont31Field {
+	var params P
+	return params.field()
+}
+
+func (z *mont31[P]) SetUint64(v uint64) *mont31[P] {
+	f := z.field()
+	z.v = f.reduce(uint64(uint32(v%uint64(f.p))) * uint64(f.r2))
+	return z
+}
+
+func (z *mont31[P]) SetBigInt(v *big.Int) *mont31[P] {
+	f := z.field()
+	r := new(big.Int).Mod(v, big.NewInt(int64(f.p)))
+	return z.SetUint64(r.Uint64())
+}
+
+// SetBytes interprets e as the bytes of a big-endian unsigned integer, and reduces it modulo p.
+func (z *mont31[P]) SetBytes(e []byte) *mont31[P] {
+	return z.SetBigInt(new(big.Int).SetBytes(e))
+}
+
+// SetString accepts the decimal numbers and the numbers with a base prefix (e.g. 0x).
+func (z *mont31[P]) SetString(s string) (*mont31[P], error) {
+	b, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, errors.New("can't parse the number into a field element")
+	}
+
+	return z.SetBigInt(b), nil
+}
+
+func (z *mont31[P]) Uint64() uint64 {
+	return uint64(z.field().reduce(uint64(z.v)))
+}
+
+func (z *mont31[P]) BigInt(res *big.Int) *big.Int {
+	return res.SetUint64(z.Uint64())
+}
+
+func (z *mont31[P]) String() string {
+	return new(big.Int).SetUint64(z.Uint64()).String()
+}
+
+func (z *mont31[P]) SetOne() *mont31[P] {
+	return z.SetUint64(1)
+}
+
+func (z *mont31[P]) SetZero() *mont31[P] {
+	z.v = 0
+	return z
+}
+
+func (z *mont31[P]) Set(x *mont31[P]) *mont31[P] {
+	z.v = x.v
+	return z
+}
+
+func (z *mont31[P]) Add(x, y *mont31[P]) *mont31[P] {
+	p := z.field().p
+	s := x.v + y.v
+	if s >= p {
+		s -= p
+	}
+
+	z.v = s
+	return z
+}
+
+func (z *mont31[P]) Sub(x, y *mont31[P]) *mont31[P] {
+	p := z.field().p
+	if x.v >= y.v {
+		z.v = x.v - y.v
+	} else {
+		z.v = x.v + p - y.v
+	}
+
+	return z
+}
+
+func (z *mont31[P]) Mul(x, y *mont31[P]) *mont31[P] {
+	z.v = z.field().reduce(uint64(x.v) * uint64(y.v))
+	return z
+}
+
+func (z *mont31[P]) Square(x *mont31[P]) *mont31[P] {
+	return z.Mul(x, x)
+}
+
+// Inverse computes x^(p-2), the inverse of zero is zero.
+func (z *mont31[P]) Inverse(x *mont31[P]) *mont31[P] {
+	e := z.field().p - 2
+	res := new(mont31[P]).SetOne()
+	base := *x
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			res.Mul(res, &base)
+		}
+		base.Square(&base)
+	}
+
+	z.v = res.v
+	return z
+}
+
+func (z *mont31[P]) Neg(x *mont31[P]) *mont31[P] {
+	if x.v == 0 {
+		z.v = 0
+	} else {
+		z.v = z.field().p - x.v
+	}
+
+	return z
+}
+
+func (z *mont31[P]) Equal(x *mont31[P]) bool {
+	return z.v == x.v
+}
+
+func (z *mont31[P]) IsZero() bool {
+	return z.v == 0
+}
+
+// Marshal returns the 4 big-endian bytes of the canonical value.
+func (z *mont31[P]) Marshal() []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(z.Uint64()))
+}
+
+// Cmp compares the canonical values of z and x.
+func (z *mont31[P]) Cmp(x *mont31[P]) int {
+	a, b := z.Uint64(), x.Uint64()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// Mersenne31 is an element of the Mersenne31 field in the canonical form,
+// the reduction uses 2^31 = 1 mod p.
+type Mersenne31 struct {
+	v uint32
+}
+
+// reduceMersenne31 reduces t < 2^62 modulo 2^31 - 1.
+func reduceMersenne31(t uint64) uint32 {
+	const p = uint64(Mersenne31Modulus)
+	t = (t & p) + (t >> 31)
+	t = (t & p) + (t >> 31)
+	if t >= p {
+		t -= p
+	}
+
+	return uint32(t)
+}
+
+func (z *Mersenne31) SetUint64(v uint64) *Mersenne31 {
+	const p = uint64(Mersenne31Modulus)
+	z.v = reduceMersenne31((v & p) + (v >> 31))
+	return z
+}
+
+func (z *Mersenne31) SetBigInt(v *big.Int) *Mersenne31 {
+	r := new(big.Int).Mod(v, big.NewInt(int64(Mersenne31Modulus)))
+	return z.SetUint64(r.Uint64())
+}
+
+// SetBytes interprets e as the bytes of a big-endian unsigned integer, and reduces it modulo p.
+func (z *Mersenne31) SetBytes(e []byte) *Mersenne31 {
+	return z.SetBigInt(new(big.Int).SetBytes(e))
+}
+
+// SetString accepts the decimal numbers and the numbers with a base prefix (e.g. 0x).
+func (z *Mersenne31) SetString(s string) (*Mersenne31, error) {
+	b, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, errors.New("can't parse the number into a field element")
+	}
+
+	return z.SetBigInt(b), nil
+}
+
+func (z *Mersenne31) Uint64() uint64 {
+	return uint64(z.v)
+}
+
+func (z *Mersenne31) BigInt(res *big.Int) *big.Int {
+	return res.SetUint64(uint64(z.v))
+}
+
+func (z *Mersenne31) String() string {
+	return new(big.Int).SetUint64(uint64(z.v)).String()
+}
+
+func (z *Mersenne31) SetOne() *Mersenne31 {
+	z.v = 1
+	return z
+}
+
+func (z *Mersenne31) SetZero() *Mersenne31 {
+	z.v = 0
+	return z
+}
+
+func (z *Mersenne31) Set(x *Mersenne31) *Mersenne31 {
+	z.v = x.v
+	return z
+}
+
+func (z *Mersenne31) Add(x, y *Mersenne31) *Mersenne31 {
+	z.v = reduceMersenne31(uint64(x.v) + uint64(y.v))
+	return z
+}
+
+func (z *Mersenne31) Sub(x, y *Mersenne31) *Mersenne31 {
+	z.v = reduceMersenne31(uint64(x.v) + uint64(Mersenne31Modulus) - uint64(y.v))
+	return z
+}
+
+func (z *Mersenne31) Mul(x, y *Mersenne31) *Mersenne31 {
+	z.v = reduceMersenne31(uint64(x.v) * uint64(y.v))
+	return z
+}
+
+func (z *Mersenne31) Square(x *Mersenne31) *Mersenne31 {
+	return z.Mul(x, x)
+}
+
+// Inverse computes x^(p-2), the inverse of zero is zero.
+func (z *Mersenne31) Inverse(x *Mersenne31) *Mersenne31 {
+	e := Mersenne31Modulus - 2
+	res := uint64(1)
+	base := uint64(x.v)
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			res = uint64(reduceMersenne31(res * base))
+		}
+		base = uint64(reduceMersenne31(base * base))
+	}
+
+	z.v = uint32(res)
+	return z
+}
+
+func (z *Mersenne31) Neg(x *Mersenne31) *Mersenne31 {
+	if x.v == 0 {
+		z.v = 0
+	} else {
+		z.v = Mersenne31Modulus - x.v
+	}
+
+	return z
+}
+
+func (z *Mersenne31) Equal(x *Mersenne31) bool {
+	return z.v == x.v
+}
+
+func (z *Mersenne31) IsZero() bool {
+	return z.v == 0
+}
+
+// Marshal returns the 4 big-endian bytes of the value.
+func (z *Mersenne31) Marshal() []byte {
+	return binary.BigEndian.AppendUint32(nil, z.v)
+}
+
+func (z *Mersenne31) Cmp(x *Mersenne31) int {
+	switch {
+	case z.v < x.v:
+		return -1
+	case z.v > x.v:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,90 @@
+package poseidon
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testField31[E Element[E]](t *testing.T, p uint32) {
+	modulus := big.NewInt(int64(p))
+	assert.Equal(t, modulus, Modulus[E]())
+	assert.Equal(t, 31, Bits[E]())
+
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		a, b := new(big.Int).SetUint64(rng.Uint64()), new(big.Int).SetUint64(rng.Uint64())
+		x, y := NewElement[E]().SetBigInt(a), NewElement[E]().SetBigInt(b)
+
+		want := new(big.Int).Add(a, b)
+		assert.Equal(t, want.Mod(want, modulus), NewElement[E]().Add(x, y).BigInt(new(big.Int)))
+
+		want = new(big.Int).Sub(a, b)
+		assert.Equal(t, want.Mod(want, modulus), NewElement[E]().Sub(x, y).BigInt(new(big.Int)))
+
+		want = new(big.Int).Mul(a, b)
+		assert.Equal(t, want.Mod(want, modulus), NewElement[E]().Mul(x, y).BigInt(new(big.Int)))
+
+		want = new(big.Int).ModInverse(a, modulus)
+		assert.Equal(t, want, NewElement[E]().Inverse(x).BigInt(new(big.Int)))
+
+		// the reduction of 64-bit integers.
+		assert.Equal(t, new(big.Int).Mod(a, modulus), NewElement[E]().SetUint64(a.Uint64()).BigInt(new(big.Int)))
+	}
+
+	x, err := NewElement[E]().SetString("0x10")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, x.Cmp(NewElement[E]().SetUint64(16)))
+	assert.Equal(t, -1, one[E]().Cmp(x))
+	assert.Equal(t, 0, zero[E]().Cmp(NewElement[E]().SetBytes(modulus.Bytes())))
+}
+
+func TestField31(t *testing.T) {
+	testField31[*BabyBear](t, BabyBearModulus)
+	testField31[*KoalaBear](t, KoalaBearModulus)
+	testField31[*Mersenne31](t, Mersenne31Modulus)
+
+	// the generators used by plonky3.
+	assert.Equal(t, uint64(31), primitiveElement[*BabyBear]().Uint64())
+	assert.Equal(t, uint64(3), primitiveElement[*KoalaBear]().Uint64())
+	assert.Equal(t, uint64(7), primitiveElement[*Mersenne31]().Uint64())
+}
+
+func TestPoseidonField31(t *testing.T) {
+	// the alpha is chosen per field, and the round numbers are the same as plonky3.
+	tests := []struct {
+		width, alpha, rf, rp int
+		gen                  func(int) (int, int, int, error)
+	}{
+		{16, 7, 8, 13, genPoseidonField31[*BabyBear]},
+		{24, 7, 8, 21, genPoseidonField31[*BabyBear]},
+		{16, 3, 8, 20, genPoseidonField31[*KoalaBear]},
+		{24, 3, 8, 23, genPoseidonField31[*KoalaBear]},
+		{16, 5, 8, 14, genPoseidonField31[*Mersenne31]},
+		{24, 5, 8, 22, genPoseidonField31[*Mersenne31]},
+	}
+
+	for _, c := range tests {
+		alpha, rf, rp, err := c.gen(c.width)
+		assert.NoError(t, err)
+		assert.Equal(t, c.alpha, alpha)
+		assert.Equal(t, c.rf, rf)
+		assert.Equal(t, c.rp, rp)
+	}
+}
+
+// genPoseidonField31 generates and verifies the poseidon constants of the given width.
+func genPoseidonField31[E Element[E]](width int) (int, int, int, error) {
+	cons, err := GenPoseidonConstants[E](width)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	if err := cons.Verify(); err != nil {
+		return 0, 0, 0, err
+	}
+
+	return int(cons.Alpha.Int64()), cons.FullRounds, cons.PartialRounds, nil
+}
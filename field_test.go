@@ -0,0 +1,48 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrimitiveElement(t *testing.T) {
+	assert.Equal(t, new(fr.Element).SetUint64(7), primitiveElement[*fr.Element]())
+
+	factors := primeFactors(big.NewInt(2 * 2 * 3 * 1000003 * 1000033))
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(1000003), big.NewInt(1000033)}, factors)
+}
+
+func testFieldInfo[E Element[E]](t *testing.T, bits, bytes, twoAdicity int, generator uint64) {
+	info := Info[E]()
+	assert.Same(t, info, Info[E]())
+	assert.Equal(t, bits, info.Bits)
+	assert.Equal(t, bytes, info.Bytes)
+	assert.Equal(t, twoAdicity, info.TwoAdicity)
+	assert.True(t, info.Generator().Equal(NewElement[E]().SetUint64(generator)))
+
+	// the modulus is a copy.
+	Modulus[E]().SetUint64(0)
+	assert.Equal(t, bits, Modulus[E]().BitLen())
+
+	x := NewElement[E]().SetUint64(3)
+	assert.True(t, NewElement[E]().Add(x, NewElement[E]().Neg(x)).IsZero())
+	assert.True(t, NewElement[E]().Neg(zero[E]()).IsZero())
+	assert.False(t, x.IsZero())
+	assert.Equal(t, append(make([]byte, bytes-1), 3), x.Marshal())
+}
+
+func TestFieldInfo(t *testing.T) {
+	testFieldInfo[*fr.Element](t, 255, 32, 32, 7)
+	testFieldInfo[*bn254fr.Element](t, 254, 32, 28, 5)
+	testFieldInfo[*goldilocks.Element](t, 64, 8, 32, 7)
+	testFieldInfo[*BabyBear](t, 31, 4, 27, 31)
+	testFieldInfo[*KoalaBear](t, 31, 4, 24, 3)
+	testFieldInfo[*Mersenne31](t, 31, 4, 1, 7)
+	testFieldInfo[*PastaFp](t, 255, 32, 32, 5)
+	testFieldInfo[*PastaFq](t, 255, 32, 32, 5)
+}
@@ -5,6 +5,7 @@ go 1.21
 require (
 	github.com/consensys/gnark-crypto v0.12.1
 	github.com/stretchr/testify v1.8.2
+	golang.org/x/crypto v0.10.0
 )
 
 require (
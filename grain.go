@@ -0,0 +1,112 @@
+package poseidon
+
+import (
+	"math/big"
+)
+
+// GrainLFSR is the Grain LFSR in a self-shrinking mode, which is used to generate
+// the round constants and the mds matrix in the reference implementation,
+// see https://eprint.iacr.org/2019/458.pdf page 13 and
+// https://extgit.iaik.tugraz.at/krypto/hadeshash.
+// The lfsr is initialized as follows:
+// 1. Initialize the state with 80 bits b0, b1, . . . , b79, where
+// (a) b0, b1 describe the field,
+// (b) bi for 2 ≤ i ≤ 5 describe the S-Box,
+// (c) bi for 6 ≤ i ≤ 17 are the binary representation of n,
+// (d) bi for 18 ≤ i ≤ 29 are the binary representation of t,
+// (e) bi for 30 ≤ i ≤ 39 are the binary representation of RF ,
+// (f) bi for 40 ≤ i ≤ 49 are the binary representation of RP , and
+// (g) bi for 50 ≤ i ≤ 79 are set to 1.
+// 2. Update the bits using bi+80 = bi+62 ⊕ bi+51 ⊕ bi+38 ⊕ bi+23 ⊕ bi+13 ⊕ bi
+// .
+// 3. Discard the first 160 bits.
+// 4. Evaluate bits in pairs: If the first bit is a 1, output the second bit. If it is a
+// 0, discard the second bit.
+// Other primitives (e.g. Rescue or Poseidon2) can reuse the same stream.
+type GrainLFSR[E Element[E]] struct {
+	// the 80-bits state, for simplicity, we use uint8 1 or 0 to represent a bit.
+	state []byte
+	// n is the number of bits of the field elements.
+	n int
+}
+
+// NewGrainLFSR initializes the grain lfsr with the given parameters,
+// field is 1 for GF(p) and 0 for GF(2^n), sbox is 0 for x^alpha and 1 for x^-1,
+// n is the field size in bits, t is the width, rf and rp are the round numbers.
+func NewGrainLFSR[E Element[E]](field, sbox, n, t, rf, rp int) *GrainLFSR[E] {
+	var bits []byte
+	bits = appendBits(bits, field, 2)
+	bits = appendBits(bits, sbox, 4)
+	bits = appendBits(bits, n, 12)
+	bits = appendBits(bits, t, 12)
+	bits = appendBits(bits, rf, 10)
+	bits = appendBits(bits, rp, 10)
+	bits = appendBits(bits, (1<<30)-1, 30)
+
+	// discard the first 160 bits.
+	for i := 0; i < 160; i++ {
+		genNewBits(bits)
+	}
+
+	return &GrainLFSR[E]{state: bits, n: n}
+}
+
+// nextBit outputs the next bit of the self-shrinking generator.
+func (g *GrainLFSR[E]) nextBit() byte {
+	newBit := genNewBits(g.state)
+	for newBit == 0 {
+		genNewBits(g.state)
+		newBit = genNewBits(g.state)
+	}
+
+	return genNewBits(g.state)
+}
+
+// NextBits returns the integer whose big-endian binary representation is the next k bits.
+func (g *GrainLFSR[E]) NextBits(k int) *big.Int {
+	res := new(big.Int)
+	for i := 0; i < k; i++ {
+		res.Lsh(res, 1)
+		if g.nextBit() == 1 {
+			res.SetBit(res, 0, 1)
+		}
+	}
+
+	return res
+}
+
+// NextFieldElement samples n bits and skips all values that would result in invalid field elements.
+func (g *GrainLFSR[E]) NextFieldElement() E {
+	for {
+		b := g.NextBits(g.n)
+		if IsValid[E](b) {
+			return NewElement[E]().SetBigInt(b)
+		}
+	}
+}
+
+// NextFieldElementNoRejection samples n bits and reduces them modulo p,
+// which is the way the reference script samples the mds matrix.
+func (g *GrainLFSR[E]) NextFieldElementNoRejection() E {
+	return NewElement[E]().SetBigInt(g.NextBits(g.n))
+}
+
+// appendBits converts a number to the bit slice.
+// For simplicity, we use uint8 1 or 0 to represent a bit.
+func appendBits(bits []byte, n, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		bitmask := 1 << i
+		b := (n & bitmask) >> i
+		bits = append(bits, byte(b))
+	}
+
+	return bits
+}
+
+// genNewBits generates new 80-bits slice and returns the newly generated bit.
+func genNewBits(bits []byte) byte {
+	newBit := bits[0] ^ bits[13] ^ bits[23] ^ bits[38] ^ bits[51] ^ bits[62]
+	newBits := append(bits, newBit)
+	copy(bits, newBits[1:])
+	return newBit
+}
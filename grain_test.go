@@ -0,0 +1,73 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrainLFSR(t *testing.T) {
+	// the first round constants and the mds matrix of circomlib (bn254, t = 3, rf = 8, rp = 57),
+	// which are generated by the reference script, see poseidon_constants.js of circomlibjs.
+	ref := NewGrainLFSR[*bn254.Element](1, 0, 254, 3, 8, 57)
+	for _, c := range []string{
+		"0ee9a592ba9a9518d05986d656f40c2114c4993c11bb29938d21d47304cd8e6e",
+		"00f1445235f2148c5986587169fc1bcd887b08d4d00868df5696fff40956e864",
+		"08dff3487e8ac99e1f29a058d0fa80b930c728730b7ab36ce879f3890ecf73f5",
+	} {
+		assert.Equal(t, felt(c), ref.NextFieldElement().BigInt(new(big.Int)))
+	}
+
+	for i := 3; i < 3*(8+57); i++ {
+		ref.NextFieldElement()
+	}
+
+	mds := genReferenceMDS[*bn254.Element](ref, 3)
+	for i, r := range [][]string{
+		{
+			"109b7f411ba0e4c9b2b70caf5c36a7b194be7c11ad24378bfedb68592ba8118b",
+			"2969f27eed31a480b9c36c764379dbca2cc8fdd1415c3dded62940bcde0bd771",
+			"143021ec686a3f330d5f9e654638065ce6cd79e28c5b3753326244ee65a1b1a7",
+		},
+		{
+			"16ed41e13bb9c0c66ae119424fddbcbc9314dc9fdbdeea55d6c64543dc4903e0",
+			"2e2419f9ec02ec394c9871c832963dc1b89d743c8c7b964029b2311687b1fe23",
+			"176cc029695ad02582a70eff08a6fd99d057e12e58e7d7b6b16cdfabc8ee2911",
+		},
+		{
+			"2b90bba00fca0589f617e7dcbfe82e0df706ab640ceb247b791a93b74e36736d",
+			"101071f0032379b697315876690f053d148d4e109f5fb065c8aacc55a0f89bfa",
+			"19a3fc0a56702bf417ba7fee3802593fa644470307043f7773279cd71d25d5e0",
+		},
+	} {
+		for j, c := range r {
+			assert.Equal(t, felt(c), mds[i][j].BigInt(new(big.Int)))
+		}
+	}
+
+	// the stream is the same as the round constants.
+	grain := NewGrainLFSR[*fr.Element](1, 1, 255, 3, 8, 55)
+	cons := genRoundConstants[*fr.Element](1, 1, 255, 3, 8, 55)
+	for i := 0; i < len(cons); i++ {
+		assert.Equal(t, cons[i], grain.NextFieldElement())
+	}
+
+	// the same parameters produce the same stream.
+	g1 := NewGrainLFSR[*fr.Element](1, 1, 255, 5, 8, 56)
+	g2 := NewGrainLFSR[*fr.Element](1, 1, 255, 5, 8, 56)
+	for i := 0; i < 10; i++ {
+		b := g1.NextBits(255)
+		assert.True(t, b.BitLen() <= 255)
+		assert.Equal(t, NewElement[*fr.Element]().SetBigInt(b), g2.NextFieldElementNoRejection())
+	}
+
+	// n bits are read as a single big-endian integer.
+	g1 = NewGrainLFSR[*fr.Element](1, 1, 255, 5, 8, 56)
+	g2 = NewGrainLFSR[*fr.Element](1, 1, 255, 5, 8, 56)
+	hi := g1.NextBits(100)
+	lo := g1.NextBits(155)
+	assert.Equal(t, new(big.Int).Or(new(big.Int).Lsh(hi, 155), lo), g2.NextBits(255))
+}
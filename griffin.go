@@ -0,0 +1,286 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// GriffinConst is the constants used in the griffin permutation,
+// see https://eprint.iacr.org/2022/403.pdf.
+// each round computes the non-linear layer, the linear layer and adds the round constants,
+// the round constants of the last round are zero.
+type GriffinConst[E Element[E]] struct {
+	// RoundConsts are the round constants of the first R-1 rounds, each has t elements.
+	RoundConsts [][]E
+	// AlphaBeta are the constants (alpha_i, beta_i) of the non-linear layer for 2 <= i < t.
+	AlphaBeta [][2]E
+	D         *big.Int
+	DInv      *big.Int
+	Width     int
+	Rounds    int
+	// halfAlpha and gamma are alpha_i/2 and beta_i - alpha_i^2/4, so that
+	// L^2 + alpha_i*L + beta_i = (L + alpha_i/2)^2 + gamma_i.
+	halfAlpha []E
+	gamma     []E
+	// perm is the network of the permutation.
+	perm *SPN[E]
+}
+
+// GenGriffinConstants generates the griffin constants as in the reference implementation,
+// the width should be 3 or a multiple of 4 (up to 24), and d is the smallest integer such that gcd(d, p-1) = 1.
+// the number of rounds is computed for the security level, see calcGriffinRounds.
+// the round constants and (alpha, beta) are sampled from SHAKE128 seeded by "Griffin" and the modulus.
+func GenGriffinConstants[E Element[E]](width, securityLevel int) (*GriffinConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	if width != 3 && (width%4 != 0 || width > 24) {
+		return nil, fmt.Errorf("width %d should be 3 or a multiple of 4 (up to 24)", width)
+	}
+
+	if securityLevel <= 0 {
+		return nil, fmt.Errorf("security level %d should be positive", securityLevel)
+	}
+
+	d := sboxAlpha[E]()
+	dInv := new(big.Int).ModInverse(big.NewInt(int64(d)), new(big.Int).Sub(Modulus[E](), big.NewInt(1)))
+	if dInv == nil {
+		return nil, fmt.Errorf("x^%d is not a permutation of the field", d)
+	}
+
+	rounds := calcGriffinRounds(width, d, securityLevel)
+
+	shake := sha3.NewShake128()
+	_, _ = shake.Write([]byte("Griffin"))
+	// the modulus in 64-bit little-endian words.
+	words := (Bits[E]() + 63) / 64
+	_, _ = shake.Write(bigToLittleEndian(Modulus[E](), 8*words))
+
+	// the round constants.
+	constants := make([][]E, rounds-1)
+	for i := 0; i < rounds-1; i++ {
+		constants[i] = make([]E, width)
+		for j := 0; j < width; j++ {
+			constants[i][j] = griffinSample[E](shake, false)
+		}
+	}
+
+	// (alpha, beta) such that alpha^2 - 4*beta is a quadratic non-residue.
+	var alpha, beta E
+	for {
+		alpha = griffinSample[E](shake, true)
+		beta = griffinSample[E](shake, true)
+		for alpha.Equal(beta) {
+			beta = griffinSample[E](shake, true)
+		}
+
+		four := NewElement[E]().SetUint64(4)
+		symbol := NewElement[E]().Square(alpha)
+		symbol.Sub(symbol, four.Mul(four, beta))
+		if big.Jacobi(symbol.BigInt(new(big.Int)), Modulus[E]()) == -1 {
+			break
+		}
+	}
+
+	// alpha_i = (i-1)*alpha, beta_i = (i-1)^2*beta.
+	alphaBeta := make([][2]E, width-2)
+	for i := 2; i < width; i++ {
+		k := NewElement[E]().SetUint64(uint64(i - 1))
+		alphaBeta[i-2][0] = NewElement[E]().Mul(alpha, k)
+		alphaBeta[i-2][1] = NewElement[E]().Mul(beta, k.Square(k))
+	}
+
+	// (alpha_i/2, beta_i - alpha_i^2/4).
+	halfAlpha := make([]E, width-2)
+	gamma := make([]E, width-2)
+	inv2 := NewElement[E]().Inverse(NewElement[E]().SetUint64(2))
+	for i := 0; i < width-2; i++ {
+		halfAlpha[i] = NewElement[E]().Mul(alphaBeta[i][0], inv2)
+		gamma[i] = NewElement[E]().Square(halfAlpha[i])
+		gamma[i].Sub(alphaBeta[i][1], gamma[i])
+	}
+
+	c := &GriffinConst[E]{
+		RoundConsts: constants,
+		AlphaBeta:   alphaBeta,
+		D:           big.NewInt(int64(d)),
+		DInv:        dInv,
+		Width:       width,
+		Rounds:      rounds,
+		halfAlpha:   halfAlpha,
+		gamma:       gamma,
+	}
+	c.perm = c.spn()
+
+	return c, nil
+}
+
+// calcGriffinRounds computes the number of rounds in the paper, R = ceil(1.2 * max(6, 1 + R_GB)),
+// where R_GB is the smallest r against the groebner basis attack such that
+// min(binomial(r*(d+t)+1, 1+t*r), binomial(d^r+1+r, 1+r))^2 >= 2^s.
+func calcGriffinRounds(t, d, securityLevel int) int {
+	target := new(big.Int).Lsh(big.NewInt(1), uint(securityLevel))
+	// d^r beyond 2^62 always satisfies the second bound.
+	limit := new(big.Int).Lsh(big.NewInt(1), 62)
+
+	r := 1
+	for ; ; r++ {
+		b := new(big.Int).Binomial(int64(r*(d+t)+1), int64(1+t*r))
+		if b.Mul(b, b).Cmp(target) < 0 {
+			continue
+		}
+
+		dr := new(big.Int).Exp(big.NewInt(int64(d)), big.NewInt(int64(r)), nil)
+		if dr.Cmp(limit) > 0 {
+			break
+		}
+
+		b.Binomial(dr.Int64()+1+int64(r), int64(1+r))
+		if b.Mul(b, b).Cmp(target) >= 0 {
+			break
+		}
+	}
+
+	m := 1 + r
+	if m < 6 {
+		m = 6
+	}
+
+	// ceil(1.2 * m).
+	return (12*m + 9) / 10
+}
+
+// griffinSample samples a field element from the xof, the ceil(n/8) bytes are read in little-endian order,
+// the unused bits of the last byte are masked, and the values not less than p (or zero if nonZero) are rejected.
+func griffinSample[E Element[E]](shake sha3.ShakeHash, nonZero bool) E {
+	n := Bits[E]()
+	buf := make([]byte, (n+7)/8)
+	for {
+		_, _ = shake.Read(buf)
+		if n%8 != 0 {
+			buf[len(buf)-1] &= byte(1<<(n%8)) - 1
+		}
+
+		// little-endian to big-endian.
+		be := make([]byte, len(buf))
+		for i := 0; i < len(buf); i++ {
+			be[len(buf)-1-i] = buf[i]
+		}
+
+		b := new(big.Int).SetBytes(be)
+		if b.Cmp(Modulus[E]()) >= 0 || (nonZero && b.Sign() == 0) {
+			continue
+		}
+
+		return NewElement[E]().SetBigInt(b)
+	}
+}
+
+// bigToLittleEndian returns the little-endian bytes of the big integer, padded to the given length.
+func bigToLittleEndian(b *big.Int, length int) []byte {
+	be := b.FillBytes(make([]byte, length))
+	le := make([]byte, length)
+	for i := 0; i < length; i++ {
+		le[length-1-i] = be[i]
+	}
+
+	return le
+}
+
+// GriffinPermute applies the griffin permutation to the whole state.
+func GriffinPermute[E Element[E]](input []*big.Int, pdsConsts *GriffinConst[E]) ([]*big.Int, error) {
+	if len(input) != pdsConsts.Width {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), pdsConsts.Width)
+	}
+
+	state := bigToElement[E](input)
+	pdsConsts.permute(state)
+
+	return elementToBig(state), nil
+}
+
+// GriffinHash computes the griffin sponge hash with the capacity of one element,
+// the input is padded with a single one and zeros to a multiple of the rate,
+// and the first rate elements of the state are returned.
+func GriffinHash[E Element[E]](input []*big.Int, pdsConsts *GriffinConst[E]) ([]*big.Int, error) {
+	rate := pdsConsts.Width - 1
+
+	padded := bigToElement[E](input)
+	padded = append(padded, one[E]())
+	for len(padded)%rate != 0 {
+		padded = append(padded, zero[E]())
+	}
+
+	state := make([]E, pdsConsts.Width)
+	for i := 0; i < len(state); i++ {
+		state[i] = zero[E]()
+	}
+
+	// absorb.
+	for i := 0; i < len(padded); i += rate {
+		for j := 0; j < rate; j++ {
+			state[j].Add(state[j], padded[i+j])
+		}
+		pdsConsts.permute(state)
+	}
+
+	// squeeze.
+	return elementToBig(state[:rate]), nil
+}
+
+// permute computes the griffin permutation in place.
+func (c *GriffinConst[E]) permute(state []E) {
+	c.perm.Permute(state, nil)
+}
+
+// spn returns the network of the griffin permutation, the round constants added after
+// the linear layer of round r are the constants added before the s-box layer of round r+1.
+func (c *GriffinConst[E]) spn() *SPN[E] {
+	external := func(dst, state []E, _ int, tmp E) {
+		productExternalMatrix(dst, state, HorizenM4, tmp)
+	}
+
+	pre := make([][]E, c.Rounds)
+	for r := 1; r < c.Rounds; r++ {
+		pre[r] = c.RoundConsts[r-1]
+	}
+
+	return &SPN[E]{
+		Schedule: FullSchedule(c.Rounds),
+		SBox: SBoxLayerFunc[E](func(state []E, _ int, _ RoundKind, tmp E) {
+			c.nonLinearLayer(state, tmp)
+		}),
+		Linear:    LinearLayerFunc[E](external),
+		Initial:   LinearLayerFunc[E](external),
+		PreConsts: pre,
+	}
+}
+
+// nonLinearLayer computes the horst-like non-linear layer in place, tmp is a scratch element:
+// y_0 = x_0^(1/d), y_1 = x_1^d, y_i = x_i * (L_i^2 + alpha_i*L_i + beta_i) for i >= 2,
+// where L_i = (i-1)*y_0 + y_1 + x_{i-1}, and x_{i-1} is omitted for i = 2.
+// the elements are updated from the last one, so x_{i-1} is still in the state when y_i is computed.
+func (c *GriffinConst[E]) nonLinearLayer(state []E, tmp E) {
+	tmp.Set(state[0])
+	Exp(state[0], tmp, c.DInv)
+	tmp.Set(state[1])
+	Exp(state[1], tmp, c.D)
+
+	for i := len(state) - 1; i >= 2; i-- {
+		// L_i + alpha_i/2.
+		tmp.SetUint64(uint64(i - 1))
+		tmp.Mul(tmp, state[0])
+		tmp.Add(tmp, state[1])
+		if i > 2 {
+			tmp.Add(tmp, state[i-1])
+		}
+		tmp.Add(tmp, c.halfAlpha[i-2])
+
+		tmp.Square(tmp)
+		tmp.Add(tmp, c.gamma[i-2])
+		state[i].Mul(state[i], tmp)
+	}
+}
@@ -0,0 +1,91 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGriffinRounds(t *testing.T) {
+	// the round numbers in the paper (128-bit security) for t = 3, 4, 8, 12, ..., 24.
+	widths := []int{3, 4, 8, 12, 16, 20, 24}
+	for d, rounds := range map[int][]int{
+		3: {16, 14, 11, 10, 10, 10, 10},
+		5: {12, 11, 9, 9, 9, 9, 9},
+	} {
+		for i, w := range widths {
+			assert.Equal(t, rounds[i], calcGriffinRounds(w, d, 128), "d = %d, t = %d", d, w)
+		}
+	}
+}
+
+func TestGriffin(t *testing.T) {
+	// the expected values are computed by an independent python port of the reference implementation,
+	// they are not the test vectors published by the authors of griffin.
+	cons, err := GenGriffinConstants[*fr.Element](3, 128)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, cons.Rounds)
+	assert.Equal(t, int64(5), cons.D.Int64())
+	assert.Equal(t, 11, len(cons.RoundConsts))
+	assert.Equal(t, hexToElement[*fr.Element]([]string{"4b74133a5743b7fb3b931a7686b7af77c6006da49d8bf85b39de9328ad2b9d87"})[0], cons.RoundConsts[0][0])
+	assert.Equal(t, hexToElement[*fr.Element]([]string{"2e516b00cf8f54ff284c394d51f863d3bbdb95e7271c46f5281e3e3bbf55dd6a"})[0], cons.AlphaBeta[0][0])
+	assert.Equal(t, hexToElement[*fr.Element]([]string{"0833e4089451bfdbf35aeed2b89b11023954a4b43567ab4b1fac69756736b33a"})[0], cons.AlphaBeta[0][1])
+
+	output, err := GriffinPermute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, hexToBig([]string{
+		"3c87fead8ab715649640b2f0e5357f2fda8e14e69893462780fb60b142901e6a",
+		"36c4bdda4f0ebf03649eb6746d3b76aeb1a5f13dbc3f55bf0ec292c5338503c4",
+		"6d794bd5ada499b6cc1f98268ff03ad0ec35a755e35a884d7dee8388e0254501",
+	}), output)
+
+	h, err := GriffinHash([]*big.Int{big.NewInt(1), big.NewInt(2)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, hexToBig([]string{
+		"16b09d8b170c353b52232a997a6cbd4b5586a122a5c1ffe8097f58c915e1d41a",
+		"3d663b65cfccc493995533d85c30082fc8b26b0e8c61153ffc640bb01e65f355",
+	}), h)
+
+	// width 8.
+	cons, err = GenGriffinConstants[*fr.Element](8, 128)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, cons.Rounds)
+
+	input := make([]*big.Int, 8)
+	for i := 0; i < len(input); i++ {
+		input[i] = big.NewInt(int64(i))
+	}
+
+	output, err = GriffinPermute(input, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, hexToBig([]string{
+		"13bc89b68b1fbea70e5a50b3586f508de62e1719821e9d722cbfef3760a7eba8",
+		"484606226ae17e4bbbcd4ade0f2a4a317f6ef7db54284b042abc16569fec69f2",
+		"59427737b4e9f5bbf4a15ffbdc9a0325f9a98c333ec469cc5c2291ee2a47b5f9",
+		"07c4d35d7f236eddfdedd61045d3fc0650ddf5730a64cd3b0375db93ec53c1ea",
+		"0beecf8d082a758c6c1b645d1a362cf19d29198ffd8d06c7912830ec49077dbd",
+		"21f38aff36e1e2b4f5087a457b5b73c7192d6d55d00c797ec04e6463a0fe355b",
+		"0d4506756ba44fbea07fc947af42cc38e796518fad1a36c6acff5dc5ab990c8b",
+		"3a5283c5e7e83a60e8cf655dc2e6f151c45d8495c1ed068ac8796bb528a07a60",
+	}), output)
+
+	_, err = GenGriffinConstants[*fr.Element](5, 128)
+	assert.Error(t, err)
+	_, err = GenGriffinConstants[*fr.Element](3, 0)
+	assert.Error(t, err)
+}
+
+func TestGriffinAllocs(t *testing.T) {
+	cons, err := GenGriffinConstants[*fr.Element](8, 128)
+	assert.NoError(t, err)
+	state := newElements[*fr.Element](8)
+	tmp := new(fr.Element)
+
+	// the non-linear layer does not allocate.
+	allocs := testing.AllocsPerRun(10, func() {
+		cons.nonLinearLayer(state, tmp)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
@@ -0,0 +1,169 @@
+package poseidon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// KimchiParams are the poseidon parameters of mina in the format of o1js,
+// that is, the json encoding of poseidonParamsKimchiFp (or poseidonParamsLegacyFp) in o1js's constants,
+// the numbers are decimal strings (or with a base prefix).
+type KimchiParams struct {
+	Mds                     [][]string `json:"mds"`
+	RoundConstants          [][]string `json:"roundConstants"`
+	FullRounds              int        `json:"fullRounds"`
+	PartialRounds           int        `json:"partialRounds"`
+	HasInitialRoundConstant bool       `json:"hasInitialRoundConstant"`
+	StateSize               int        `json:"stateSize"`
+	Rate                    int        `json:"rate"`
+	Power                   int        `json:"power"`
+}
+
+// ReadKimchiParams reads the parameters in the json format of o1js.
+func ReadKimchiParams(r io.Reader) (*KimchiParams, error) {
+	var params KimchiParams
+	if err := json.NewDecoder(r).Decode(&params); err != nil {
+		return nil, fmt.Errorf("decode kimchi params err: %w", err)
+	}
+
+	return &params, nil
+}
+
+// KimchiConst is the constants used in the kimchi permutation, which has full rounds only,
+// each round computes the s-box layer x^alpha, the mds matrix (M*x) and adds the round constants,
+// kimchi uses 55 rounds with alpha 7 and no initial round constants, and the legacy hash of mina
+// uses 63 rounds with alpha 5 and the initial round constants.
+type KimchiConst[E Element[E]] struct {
+	Mds Matrix[E]
+	// InitialConsts are added before the first round, it is nil if there is no initial round constant.
+	InitialConsts []E
+	RoundConsts   [][]E
+	Alpha         *big.Int
+	Width         int
+	Rate          int
+	Rounds        int
+}
+
+// NewKimchiConstants creates the kimchi constants from the parameters of o1js.
+func NewKimchiConstants[E Element[E]](params *KimchiParams) (*KimchiConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	width := params.StateSize
+	if params.Rate <= 0 || params.Rate >= width {
+		return nil, fmt.Errorf("rate %d should be in (0, %d)", params.Rate, width)
+	}
+
+	if params.PartialRounds != 0 {
+		return nil, fmt.Errorf("partial rounds %d should be zero", params.PartialRounds)
+	}
+
+	if params.FullRounds <= 0 {
+		return nil, fmt.Errorf("full rounds %d should be positive", params.FullRounds)
+	}
+
+	if params.Power < 3 {
+		return nil, fmt.Errorf("power %d is invalid", params.Power)
+	}
+
+	mds, err := decimalToMatrix[E](params.Mds)
+	if err != nil {
+		return nil, fmt.Errorf("parse mds matrix err: %w", err)
+	}
+
+	if len(mds) != width || !IsSquareMatrix(mds) {
+		return nil, fmt.Errorf("mds matrix should be a %d x %d matrix", width, width)
+	}
+
+	rounds := params.FullRounds
+	if params.HasInitialRoundConstant {
+		rounds++
+	}
+
+	if len(params.RoundConstants) != rounds {
+		return nil, fmt.Errorf("round constants length %d is inconsistent, want %d", len(params.RoundConstants), rounds)
+	}
+
+	constants := make([][]E, rounds)
+	for i := 0; i < rounds; i++ {
+		if len(params.RoundConstants[i]) != width {
+			return nil, fmt.Errorf("round constants %d should have %d elements", i, width)
+		}
+
+		if constants[i], err = decimalToElement[E](params.RoundConstants[i]); err != nil {
+			return nil, fmt.Errorf("parse round constants err: %w", err)
+		}
+	}
+
+	cons := &KimchiConst[E]{
+		Mds:         mds,
+		RoundConsts: constants,
+		Alpha:       big.NewInt(int64(params.Power)),
+		Width:       width,
+		Rate:        params.Rate,
+		Rounds:      params.FullRounds,
+	}
+
+	if params.HasInitialRoundConstant {
+		cons.InitialConsts = constants[0]
+		cons.RoundConsts = constants[1:]
+	}
+
+	return cons, nil
+}
+
+// spn returns the network of the kimchi permutation, the round constants are added after the mds matrix.
+func (c *KimchiConst[E]) spn() *SPN[E] {
+	linear := func(dst, state []E, r int, tmp E) {
+		productMatrix(dst, state, c.Mds, tmp)
+		addConsts(dst, c.RoundConsts[r])
+	}
+
+	return &SPN[E]{
+		Schedule:      FullSchedule(c.Rounds),
+		SBox:          PowerSBox[E]{Alpha: c.Alpha},
+		Linear:        LinearLayerFunc[E](linear),
+		InitialConsts: c.InitialConsts,
+	}
+}
+
+// KimchiPermute applies the kimchi permutation to the whole state.
+func KimchiPermute[E Element[E]](input []*big.Int, pdsConsts *KimchiConst[E]) ([]*big.Int, error) {
+	if len(input) != pdsConsts.Width {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), pdsConsts.Width)
+	}
+
+	state, err := canonicalToElement[E](input)
+	if err != nil {
+		return nil, err
+	}
+
+	return elementToBig(pdsConsts.spn().Permute(state, nil)), nil
+}
+
+// KimchiHash computes the sponge hash as o1js's Poseidon.hash, the input is padded with zeros to a multiple of the rate,
+// and absorbed into the zero state, the empty input is permuted once, and the output is state[0].
+func KimchiHash[E Element[E]](input []*big.Int, pdsConsts *KimchiConst[E]) (*big.Int, error) {
+	padded, err := canonicalToElement[E](input)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(padded) == 0 || len(padded)%pdsConsts.Rate != 0 {
+		padded = append(padded, zero[E]())
+	}
+
+	spn := pdsConsts.spn()
+	state := newElements[E](pdsConsts.Width)
+	for i := 0; i < len(padded); i += pdsConsts.Rate {
+		for j := 0; j < pdsConsts.Rate; j++ {
+			state[j].Add(state[j], padded[i+j])
+		}
+		spn.Permute(state, nil)
+	}
+
+	return state[0].BigInt(new(big.Int)), nil
+}
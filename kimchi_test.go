@@ -0,0 +1,155 @@
+package poseidon
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// kimchiTestParams converts the poseidon constants with rp=0 into the parameters of o1js,
+// with the transposed mds matrix since kimchi computes M*x, and the extra constants of the last round.
+func kimchiTestParams(cons *PoseidonConst[*PastaFp], last []*PastaFp, power int) *KimchiParams {
+	width := len(last)
+	mds := make([][]string, width)
+	for i := 0; i < width; i++ {
+		mds[i] = make([]string, width)
+		for j := 0; j < width; j++ {
+			mds[i][j] = cons.Mds.m[j][i].String()
+		}
+	}
+
+	constants := make([][]string, cons.FullRounds+1)
+	for r := 0; r <= cons.FullRounds; r++ {
+		round := last
+		if r < cons.FullRounds {
+			round = cons.RoundConsts[r*width : (r+1)*width]
+		}
+
+		constants[r] = make([]string, width)
+		for j := 0; j < width; j++ {
+			constants[r][j] = round[j].String()
+		}
+	}
+
+	return &KimchiParams{
+		Mds:                     mds,
+		RoundConstants:          constants,
+		FullRounds:              cons.FullRounds,
+		HasInitialRoundConstant: true,
+		StateSize:               width,
+		Rate:                    width - 1,
+		Power:                   power,
+	}
+}
+
+func TestKimchiPermute(t *testing.T) {
+	// with the initial round constants, kimchi is poseidon with full rounds only,
+	// followed by the constants of the last round. the constants are generated, not the ones of o1js,
+	// so this checks the round structure rather than the parity with o1js.
+	cons, err := GenCustomPoseidonConstants[*PastaFp](3, 1, 1, 55, 0, genMDS[*PastaFp](3))
+	assert.NoError(t, err)
+	cons.Alpha = big.NewInt(7)
+
+	last := []*PastaFp{new(PastaFp).SetUint64(1), new(PastaFp).SetUint64(2), new(PastaFp).SetUint64(3)}
+	kimchi, err := NewKimchiConstants[*PastaFp](kimchiTestParams(cons, last, 7))
+	assert.NoError(t, err)
+
+	input := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	want, err := Permute(input, cons)
+	assert.NoError(t, err)
+	for i := 0; i < len(want); i++ {
+		want[i].Add(want[i], big.NewInt(int64(i+1)))
+	}
+
+	get, err := KimchiPermute(input, kimchi)
+	assert.NoError(t, err)
+	assert.Equal(t, want, get)
+
+	_, err = KimchiPermute(input[:2], kimchi)
+	assert.Error(t, err)
+	_, err = KimchiPermute([]*big.Int{big.NewInt(1), big.NewInt(2), Modulus[*PastaFp]()}, kimchi)
+	assert.Error(t, err)
+}
+
+func TestKimchiHash(t *testing.T) {
+	cons, err := GenCustomPoseidonConstants[*PastaFp](3, 1, 1, 8, 0, genMDS[*PastaFp](3))
+	assert.NoError(t, err)
+	params := kimchiTestParams(cons, []*PastaFp{new(PastaFp), new(PastaFp), new(PastaFp)}, 7)
+	params.RoundConstants = params.RoundConstants[1:]
+	params.HasInitialRoundConstant = false
+	kimchi, err := NewKimchiConstants[*PastaFp](params)
+	assert.NoError(t, err)
+
+	zero := big.NewInt(0)
+	a, b, c := big.NewInt(1), big.NewInt(2), big.NewInt(3)
+
+	// the empty input permutes the zero state once.
+	h, err := KimchiHash(nil, kimchi)
+	assert.NoError(t, err)
+	out, err := KimchiPermute([]*big.Int{zero, zero, zero}, kimchi)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	// the input is padded with zeros.
+	h1, err := KimchiHash([]*big.Int{a}, kimchi)
+	assert.NoError(t, err)
+	h2, err := KimchiHash([]*big.Int{a, zero}, kimchi)
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	// absorb 2 elements at a time.
+	out, err = KimchiPermute([]*big.Int{a, b, zero}, kimchi)
+	assert.NoError(t, err)
+	out[0].Add(out[0], c).Mod(out[0], Modulus[*PastaFp]())
+	out, err = KimchiPermute(out, kimchi)
+	assert.NoError(t, err)
+	h, err = KimchiHash([]*big.Int{a, b, c}, kimchi)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+}
+
+func TestReadKimchiParams(t *testing.T) {
+	params, err := ReadKimchiParams(strings.NewReader(`{
+		"mds": [["1", "0"], ["0", "1"]],
+		"roundConstants": [["1", "2"], ["0x3", "4"]],
+		"fullRounds": 2,
+		"partialRounds": 0,
+		"hasInitialRoundConstant": false,
+		"stateSize": 2,
+		"rate": 1,
+		"power": 7
+	}`))
+	assert.NoError(t, err)
+
+	cons, err := NewKimchiConstants[*PastaFq](params)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cons.Rounds)
+	assert.Equal(t, big.NewInt(7), cons.Alpha)
+	assert.Nil(t, cons.InitialConsts)
+	assert.True(t, cons.RoundConsts[1][0].Equal(new(PastaFq).SetUint64(3)))
+
+	tests := []struct {
+		modify func(p *KimchiParams)
+		want   string
+	}{
+		{func(p *KimchiParams) { p.PartialRounds = 1 }, "partial rounds"},
+		{func(p *KimchiParams) { p.Rate = 2 }, "rate"},
+		{func(p *KimchiParams) { p.HasInitialRoundConstant = true }, "round constants length"},
+		{func(p *KimchiParams) { p.Mds = p.Mds[1:] }, "mds matrix"},
+		{func(p *KimchiParams) { p.RoundConstants[0][0] = Modulus[*PastaFq]().String() }, "not in the field"},
+	}
+
+	for _, c := range tests {
+		p := *params
+		p.RoundConstants = [][]string{{"1", "2"}, {"3", "4"}}
+		c.modify(&p)
+		_, err := NewKimchiConstants[*PastaFq](&p)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), c.want)
+	}
+
+	_, err = ReadKimchiParams(strings.NewReader("{"))
+	assert.Error(t, err)
+}
@@ -100,7 +100,7 @@ func IsVecEqual[E Element[E]](a, b Vector[E]) bool {
 	}
 
 	for i := 0; i < len(a); i++ {
-		if a[i].Cmp(b[i]) != 0 {
+		if !a[i].Equal(b[i]) {
 			return false
 		}
 	}
@@ -132,7 +132,7 @@ func IsInvertible[E Element[E]](m Matrix[E]) bool {
 	}
 
 	for i := 0; i < row(tmp); i++ {
-		if upper[i][i].Cmp(zero[E]()) == 0 {
+		if upper[i][i].IsZero() {
 			return false
 		}
 	}
@@ -249,7 +249,7 @@ func MakeIdentity[E Element[E]](t int) Matrix[E] {
 func IsIdentity[E Element[E]](m Matrix[E]) bool {
 	for i := 0; i < row(m); i++ {
 		for j := 0; j < column(m); j++ {
-			if ((i == j) && m[i][j].Cmp(one[E]()) != 0) || ((i != j) && (m[i][j].Cmp(zero[E]()) != 0)) {
+			if ((i == j) && !m[i][j].Equal(one[E]())) || ((i != j) && (!m[i][j].IsZero())) {
 				return false
 			}
 		}
@@ -265,7 +265,7 @@ func IsEqual[E Element[E]](a, b Matrix[E]) bool {
 
 	for i := 0; i < row(a); i++ {
 		for j := 0; j < column(a); j++ {
-			if a[i][j].Cmp(b[i][j]) != 0 {
+			if !a[i][j].Equal(b[i][j]) {
 				return false
 			}
 		}
@@ -312,12 +312,12 @@ func minor[E Element[E]](m Matrix[E], rowIndex, columnIndex int) (Matrix[E], err
 
 // determine if the first k elements are zero.
 func isFirstKZero[E Element[E]](v Vector[E], k int) bool {
-	if k == 0 && v[0].Cmp(zero[E]()) == 0 {
+	if k == 0 && v[0].IsZero() {
 		return false
 	}
 
 	for i := 0; i < k; i++ {
-		if v[i].Cmp(zero[E]()) != 0 {
+		if !v[i].IsZero() {
 			return false
 		}
 	}
@@ -333,7 +333,7 @@ func findNonZero[E Element[E]](m Matrix[E], index int) (pivot E, pivotIndex int,
 	}
 
 	for i := 0; i < row(m); i++ {
-		if m[i][index].Cmp(zero[E]()) != 0 {
+		if !m[i][index].IsZero() {
 			pivot = m[i][index]
 			pivotIndex = i
 			break
@@ -357,7 +357,7 @@ func eliminate[E Element[E]](m, shadow Matrix[E], columnIndex int) (Matrix[E], M
 			continue
 		}
 
-		if m[i][columnIndex].Cmp(zero[E]()) != 0 {
+		if !m[i][columnIndex].IsZero() {
 			factor := NewElement[E]().Mul(m[i][columnIndex], pivotInv)
 
 			scalarPivot := ScalarVecMul(factor, m[pivotIndex])
@@ -413,7 +413,7 @@ func reverseRows[E Element[E]](m Matrix[E]) Matrix[E] {
 func zeroNums[E Element[E]](v Vector[E], n int) bool {
 	count := 0
 	for i := 0; i < len(v); i++ {
-		if v[i].Cmp(zero[E]()) != 0 {
+		if !v[i].IsZero() {
 			break
 		}
 		count++
@@ -485,7 +485,7 @@ func reduceToIdentity[E Element[E]](m, shadow Matrix[E]) (Matrix[E], Matrix[E],
 		indexi := row(m) - i - 1
 
 		factor := m[indexi][indexi]
-		if factor.Cmp(zero[E]()) == 0 {
+		if factor.IsZero() {
 			return nil, nil, errors.New("cannot compute the result!")
 		}
 
@@ -131,7 +131,7 @@ func TestMinor(t *testing.T) {
 	}
 }
 
-func TestcopyMatrix(t *testing.T) {
+func TestCopyMatrix(t *testing.T) {
 	m := Matrix[*fr.Element]{{oneE, two, three}, {four, five, six}, {seven, eight, nine}}
 
 	testMatrix := []struct {
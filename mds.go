@@ -1,6 +1,7 @@
 package poseidon
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -42,6 +43,21 @@ type SparseMatrix[E Element[E]] struct {
 	V Vector[E]
 }
 
+// matrix recovers the width*width sparse matrix m''.
+func (s *SparseMatrix[E]) matrix(width int) (Matrix[E], error) {
+	if len(s.WHat) != width || len(s.V) != width-1 {
+		return nil, errors.New("sparse matrix length err")
+	}
+
+	m := MakeIdentity[E](width)
+	for i := 0; i < width; i++ {
+		m[i][0] = s.WHat[i]
+	}
+	copy(m[0][1:], s.V)
+
+	return m, nil
+}
+
 // generate the mds (cauchy) matrix, which is invertible, and
 // its sub-matrices are invertible as well.
 func genMDS[E Element[E]](t int) Matrix[E] {
@@ -81,6 +97,56 @@ regen:
 	return m
 }
 
+// genReferenceMDS samples the cauchy matrix M_ij = 1/(x_i + y_j) from the grain lfsr as in the reference script
+// (https://extgit.iaik.tugraz.at/krypto/hadeshash), which continues the stream of the round constants.
+// the 2t elements are sampled without rejection, and are resampled if there are duplicates or x_i + y_j = 0.
+// the reference script computes M*x, so the transpose is returned to be used as state*M.
+// note that the additional checks of the reference script against the invariant subspaces are not applied,
+// the first sampled matrix is used.
+func genReferenceMDS[E Element[E]](grain *GrainLFSR[E], t int) Matrix[E] {
+	for {
+		xy := make([]E, 2*t)
+		for i := 0; i < 2*t; i++ {
+			xy[i] = grain.NextFieldElementNoRejection()
+		}
+
+		if hasDuplicates(xy) {
+			continue
+		}
+
+		m := make([][]E, t)
+		valid := true
+		for i := 0; i < t && valid; i++ {
+			m[i] = make([]E, t)
+			for j := 0; j < t; j++ {
+				m[i][j] = NewElement[E]().Add(xy[i], xy[t+j])
+				if m[i][j].IsZero() {
+					valid = false
+					break
+				}
+				m[i][j].Inverse(m[i][j])
+			}
+		}
+
+		if valid {
+			return transpose[E](m)
+		}
+	}
+}
+
+// hasDuplicates determines if there are duplicate elements.
+func hasDuplicates[E Element[E]](v []E) bool {
+	for i := 0; i < len(v); i++ {
+		for j := i + 1; j < len(v); j++ {
+			if v[i].Equal(v[j]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // derive the mds matrices from m.
 func deriveMatrices[E Element[E]](m Matrix[E]) (*mdsMatrices[E], error) {
 	mInv, err := Invert(m)
@@ -0,0 +1,60 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// GenOrchardConstants generates the constants of P128Pow5T3 (width 3, rf=8, rp=56, x^5) over the pallas base field,
+// which is used by zcash orchard, halo2_gadgets generates them by the reference script (with the first sampled mds matrix),
+// see GenReferenceConstants.
+func GenOrchardConstants() (*PoseidonConst[*PastaFp], error) {
+	return GenReferenceConstants[*PastaFp](3, 8, 56)
+}
+
+// ConstantLengthHash computes the hash of halo2's ConstantLength<L> domain with the rate width-1,
+// the capacity element (the last one) is initialized to L*2^64, the input is padded with zeros to a multiple of the rate,
+// and absorbed into the rate elements, the output is state[0].
+func ConstantLengthHash[E Element[E]](input []*big.Int, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
+	width := row(pdsConsts.Mds.m)
+	elems, err := canonicalToElement[E](input)
+	if err != nil {
+		return nil, err
+	}
+
+	spn := pdsConsts.staticSPN()
+	permute := func(state []E) {
+		spn.Permute(state, nil)
+	}
+
+	return constantLengthSponge(elems, len(input), width, permute)
+}
+
+// constantLengthSponge initializes the capacity element (the last one) to length*2^64,
+// then the elements are padded with zeros to a multiple of the rate (width-1), and added to the rate elements
+// before each permutation, the output is state[0].
+func constantLengthSponge[E Element[E]](elems []E, length, width int, permute func([]E)) (*big.Int, error) {
+	rate := width - 1
+
+	// length*2^64 should be in the field.
+	capacity := new(big.Int).Lsh(big.NewInt(int64(length)), 64)
+	if !IsValid[E](capacity) {
+		return nil, fmt.Errorf("input length %d is too large for the field", length)
+	}
+
+	padded := append([]E{}, elems...)
+	for len(padded) == 0 || len(padded)%rate != 0 {
+		padded = append(padded, zero[E]())
+	}
+
+	state := newElements[E](width)
+	state[rate].SetBigInt(capacity)
+	for i := 0; i < len(padded); i += rate {
+		for j := 0; j < rate; j++ {
+			state[j].Add(state[j], padded[i+j])
+		}
+		permute(state)
+	}
+
+	return state[0].BigInt(new(big.Int)), nil
+}
@@ -0,0 +1,65 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrchardConstants(t *testing.T) {
+	cons, err := GenOrchardConstants()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, cons.FullRounds)
+	assert.Equal(t, 56, cons.PartialRounds)
+	assert.Equal(t, big.NewInt(5), cons.Alpha)
+	assert.Equal(t, 64*3, len(cons.RoundConsts))
+
+	// the first round constant of halo2_gadgets (poseidon/primitives/fp.rs).
+	assert.Equal(t, felt("360d7470611e473d353f628f76d110f34e71162f31003b7057538c2596426303"), cons.RoundConsts[0].BigInt(new(big.Int)))
+
+	// permute(0, 1, 2), a regression value computed by this library,
+	// it is not the test vector of halo2_gadgets (poseidon/primitives/test_vectors.rs), which is not included yet.
+	out, err := Permute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{
+		felt("2a526acd0b64b45394efb364f966240ff7e69a71d0b642a0aeb1bc024aeca456"),
+		felt("13c5d1568b4aa43076ff7dae343d5512dcd42e7fbed9dafe012a3e9628e5b82a"),
+		felt("a49c868c6976544256fcd597984561af7cfdfe1bda42c7b359029a1d34e9ddd"),
+	}, out)
+}
+
+func TestConstantLengthHash(t *testing.T) {
+	cons, err := GenOrchardConstants()
+	assert.NoError(t, err)
+
+	a, b, c := big.NewInt(1), big.NewInt(2), big.NewInt(3)
+	zero := big.NewInt(0)
+
+	// L = 2 fills the rate, the capacity is 2*2^64.
+	h, err := ConstantLengthHash([]*big.Int{a, b}, cons)
+	assert.NoError(t, err)
+	out, err := Permute([]*big.Int{a, b, new(big.Int).Lsh(big.NewInt(2), 64)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	// L = 3 is padded with a zero.
+	h, err = ConstantLengthHash([]*big.Int{a, b, c}, cons)
+	assert.NoError(t, err)
+	out, err = Permute([]*big.Int{a, b, new(big.Int).Lsh(big.NewInt(3), 64)}, cons)
+	assert.NoError(t, err)
+	out[0].Add(out[0], c).Mod(out[0], Modulus[*PastaFp]())
+	out, err = Permute(out, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	// L = 0 permutes the initial state once.
+	h, err = ConstantLengthHash(nil, cons)
+	assert.NoError(t, err)
+	out, err = Permute([]*big.Int{zero, zero, zero}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[0], h)
+
+	_, err = ConstantLengthHash([]*big.Int{a, Modulus[*PastaFp]()}, cons)
+	assert.Error(t, err)
+}
@@ -11,7 +11,14 @@ const SecurityLevel int = 128
 
 // we refer the rust implement and supplementary material shown in the paper to generate the round numbers.
 // see https://extgit.iaik.tugraz.at/krypto/hadeshash.
+// the formulas of isRoundNumberSecure are tuned for x^5 over ~255-bit fields,
+// the other fields (e.g. goldilocks with x^7, whose elements are smaller than the security level)
+// use the formulas for the general alpha.
 func calcRoundNumbers[E Element[E]](t int, securityMargin bool) (rf, rp int) {
+	if alpha := sboxAlpha[E](); alpha != 5 || Bits[E]() <= SecurityLevel {
+		return calcAlphaRoundNumbers[E](t, alpha, securityMargin)
+	}
+
 	rf, rp = 0, 0
 	min := math.MaxInt64
 
@@ -68,77 +75,62 @@ func isRoundNumberSecure[E Element[E]](t, rf, rp int) bool {
 	return float64(rf) >= max
 }
 
-// appendBits converts a number to the bit slice.
-// For simplicity, we use uint8 1 or 0 to represent a bit.
-func appendBits(bits []byte, n, size int) []byte {
-	for i := size - 1; i >= 0; i-- {
-		bitmask := 1 << i
-		b := (n & bitmask) >> i
-		bits = append(bits, byte(b))
-	}
+// calcAlphaRoundNumbers computes the round numbers for the general alpha as in the script of the reference implementation,
+// the interpolation and groebner basis attacks are bounded by min(M, n) bits, which accounts for the small fields.
+func calcAlphaRoundNumbers[E Element[E]](t, alpha int, securityMargin bool) (rf, rp int) {
+	min := math.MaxInt64
 
-	return bits
-}
+	// Brute-force approach
+	for rpt := 1; rpt < 500; rpt++ {
+		for rft := 4; rft < 100; rft += 2 {
+			if isAlphaRoundNumberSecure[E](t, rft, rpt, alpha) {
+				rft, rpt := rft, rpt
+				if securityMargin {
+					rft, rpt = rft+2, int(math.Ceil(1.075*float64(rpt)))
+				}
+				sboxn := t*rft + rpt
+				if sboxn < min || (sboxn == min && rft < rf) {
+					rf, rp = rft, rpt
+					min = sboxn
+				}
+			}
+		}
+	}
 
-// genNewBits generates new 80-bits slice and returns the newly generated bit.
-func genNewBits(bits []byte) byte {
-	newBit := bits[0] ^ bits[13] ^ bits[23] ^ bits[38] ^ bits[51] ^ bits[62]
-	newBits := append(bits, newBit)
-	copy(bits, newBits[1:])
-	return newBit
+	return
 }
 
-// nextByte converts bits to byte.
-func nextByte(bits []byte, bitCount int) byte {
-	var b byte
-	for i := 0; i < bitCount; i++ {
-		newBit := genNewBits(bits)
-		for newBit == 0 {
-			genNewBits(bits)
-			newBit = genNewBits(bits)
-		}
-		newBit = genNewBits(bits)
-
-		b <<= 1
-		if newBit == 1 {
-			b += 1
-		}
+// isAlphaRoundNumberSecure determines if the round numbers are secure for the general alpha.
+func isAlphaRoundNumberSecure[E Element[E]](t, rf, rp, alpha int) bool {
+	n := float64(Bits[E]())
+	m := float64(SecurityLevel)
+	// log2(p)
+	logp, _ := new(big.Float).SetInt(Modulus[E]()).Float64()
+	logp = math.Log2(logp)
+	// log_alpha(2)
+	log2a := 1 / math.Log2(float64(alpha))
+
+	// Statistical Attacks.
+	rf0 := 10.0
+	if m <= math.Floor(logp-float64(alpha-1)/2)*float64(t+1) {
+		rf0 = 6
 	}
-	return b
-}
 
-// getBytes generates a random byte slice.
-func getBytes[E Element[E]](bits []byte, fieldsize int) []byte {
-	// Only prime fields are supported, and they always have reminder bits.
-	remainderBits := fieldsize % 8
+	// Interpolation Attack.
+	rf1 := 1 + math.Ceil(log2a*math.Min(m, n)) + math.Ceil(math.Log2(float64(t))*log2a) - float64(rp)
 
-	bytes := Bytes[E]()
-	buf := make([]byte, bytes)
-	buf[0] = nextByte(bits, remainderBits)
+	// Gröbner Basis Attacks.
+	rf2 := log2a*math.Min(m, logp) - float64(rp)
+	rf3 := float64(t) - 1 + log2a*math.Min(m/float64(t+1), logp/2) - float64(rp)
+	rf4 := (float64(t) - 2 + m/(2*math.Log2(float64(alpha))) - float64(rp)) / float64(t-1)
 
-	// The first byte is already set.
-	for i := 1; i < bytes; i++ {
-		buf[i] = nextByte(bits, 8)
-	}
+	max := math.Max(math.Max(math.Ceil(rf0), math.Ceil(rf1)), math.Max(math.Max(math.Ceil(rf2), math.Ceil(rf3)), math.Ceil(rf4)))
 
-	return buf
+	return float64(rf) >= max
 }
 
 // The round constants are generated using the Grain LFSR in a self-shrinking
-// mode:
-// 1. Initialize the state with 80 bits b0, b1, . . . , b79, where
-// (a) b0, b1 describe the field,
-// (b) bi for 2 ≤ i ≤ 5 describe the S-Box,
-// (c) bi for 6 ≤ i ≤ 17 are the binary representation of n,
-// (d) bi for 18 ≤ i ≤ 29 are the binary representation of t,
-// (e) bi for 30 ≤ i ≤ 39 are the binary representation of RF ,
-// (f) bi for 40 ≤ i ≤ 49 are the binary representation of RP , and
-// (g) bi for 50 ≤ i ≤ 79 are set to 1.
-// 2. Update the bits using bi+80 = bi+62 ⊕ bi+51 ⊕ bi+38 ⊕ bi+23 ⊕ bi+13 ⊕ bi
-// .
-// 3. Discard the first 160 bits.
-// 4. Evaluate bits in pairs: If the first bit is a 1, output the second bit. If it is a
-// 0, discard the second bit.
+// mode, see GrainLFSR for more details.
 // Using this method, the generation of round constants depends on the specific
 // instance, and thus different round constants are used even if some of the chosen
 // parameters (e.g., n and t) are the same.
@@ -147,30 +139,11 @@ func getBytes[E Element[E]](bits []byte, fieldsize int) []byte {
 func genRoundConstants[E Element[E]](field, sbox int, fieldsize, t, rf, rp int) []E {
 	numCons := (rf + rp) * t
 
-	var bits []byte
-	bits = appendBits(bits, field, 2)
-	bits = appendBits(bits, sbox, 4)
-	bits = appendBits(bits, fieldsize, 12)
-	bits = appendBits(bits, t, 12)
-	bits = appendBits(bits, rf, 10)
-	bits = appendBits(bits, rp, 10)
-	bits = appendBits(bits, (1<<30)-1, 30)
-
-	for i := 0; i < 160; i++ {
-		genNewBits(bits)
-	}
+	grain := NewGrainLFSR[E](field, sbox, fieldsize, t, rf, rp)
 
 	roundConsts := make([]E, numCons)
 	for i := 0; i < numCons; i++ {
-		for {
-			buf := getBytes[E](bits, fieldsize)
-			bufBigint := new(big.Int).SetBytes(buf)
-			// Skip all buffers that would result in invalid field elements.
-			if IsValid[E](bufBigint) {
-				roundConsts[i] = NewElement[E]().SetBytes(buf)
-				break
-			}
-		}
+		roundConsts[i] = grain.NextFieldElement()
 	}
 
 	return roundConsts
@@ -236,8 +209,8 @@ func genCompressedRoundConstants[E Element[E]](width, rf, rp int, roundConstants
 		comRoundConstants[(rf/2+1)*width+i] = partialKeys[rp-i-1]
 	}
 
-	// final 3 full-rounds.
-	for i := 1; i < rf/2; i++ {
+	// the second half full-rounds, which has one more round if rf is odd (no partial round).
+	for i := 1; i < rf-rf/2; i++ {
 		constants := roundConstants[(rf/2+rp+i)*width : (rf/2+rp+i+1)*width]
 		inv, err := RightMatMul(constants, mInv)
 		if err != nil {
@@ -248,3 +221,104 @@ func genCompressedRoundConstants[E Element[E]](width, rf, rp int, roundConstants
 
 	return comRoundConstants, nil
 }
+
+// uncompressRoundConstants returns the round constants whose compressed form is the given one (see genCompressedRoundConstants),
+// it is used when only the compressed constants are published. the result is equivalent but not unique,
+// the constants of the partial rounds (except the first one) and of the next full round are (k, 0, ..., 0)*M.
+func uncompressRoundConstants[E Element[E]](width, rf, rp int, compressed []E, mds Matrix[E]) ([]E, error) {
+	if len(compressed) != rf*width+rp {
+		return nil, fmt.Errorf("compressed round constants length %d is inconsistent, want %d", len(compressed), rf*width+rp)
+	}
+
+	roundConstants := make([]E, (rf+rp)*width)
+	setRound := func(round int, v []E) error {
+		c, err := RightMatMul(v, mds)
+		if err != nil {
+			return fmt.Errorf("round %d constants mul err: %w", round, err)
+		}
+		copy(roundConstants[round*width:(round+1)*width], c)
+		return nil
+	}
+
+	// the first round constants.
+	copy(roundConstants[:width], compressed[:width])
+
+	// the first half full-rounds and the first partial round.
+	for i := 1; i <= rf/2; i++ {
+		if err := setRound(i, compressed[i*width:(i+1)*width]); err != nil {
+			return nil, err
+		}
+	}
+
+	// the partial keys, the i-th key is added at the round rf/2+i+1.
+	for i := 0; i < rp; i++ {
+		key := make([]E, width)
+		key[0] = compressed[(rf/2+1)*width+i]
+		for j := 1; j < width; j++ {
+			key[j] = zero[E]()
+		}
+
+		if err := setRound(rf/2+i+1, key); err != nil {
+			return nil, err
+		}
+	}
+
+	// the second half full-rounds.
+	for i := 1; i < rf-rf/2; i++ {
+		offset := (rf/2+i)*width + rp
+		if err := setRound(rf/2+rp+i, compressed[offset:offset+width]); err != nil {
+			return nil, err
+		}
+	}
+
+	return roundConstants, nil
+}
+
+// calcPoseidon2RoundNumbers computes the round numbers of poseidon2 with the security margin,
+// we refer the script in https://github.com/HorizenLabs/poseidon2/blob/main/poseidon2_rust_params.sage,
+// which takes the general alpha and the attack in https://eprint.iacr.org/2023/537.pdf into account.
+func calcPoseidon2RoundNumbers[E Element[E]](t, alpha int) (rf, rp int) {
+	min := math.MaxInt64
+
+	// Brute-force approach
+	for rpt := 1; rpt < 500; rpt++ {
+		for rft := 4; rft < 100; rft += 2 {
+			if isPoseidon2RoundNumberSecure[E](t, rft, rpt, alpha) {
+				// security margin.
+				rft, rpt := rft+2, int(math.Ceil(1.075*float64(rpt)))
+				sboxn := t*rft + rpt
+				if sboxn < min || (sboxn == min && rft < rf) {
+					rf, rp = rft, rpt
+					min = sboxn
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// isPoseidon2RoundNumberSecure determines if the round numbers are secure for the general alpha,
+// and additionally takes the attack in https://eprint.iacr.org/2023/537.pdf into account.
+func isPoseidon2RoundNumberSecure[E Element[E]](t, rf, rp, alpha int) bool {
+	if !isAlphaRoundNumberSecure[E](t, rf, rp, alpha) {
+		return false
+	}
+
+	// https://eprint.iacr.org/2023/537.pdf
+	m := float64(SecurityLevel)
+	r := math.Floor(float64(t) / 3)
+	over := float64((rf-1)*t+2*rp+alpha) + r + r*float64(rf)/2
+	under := r*float64(rf)/2 + float64(rp+alpha)
+	cost := math.Ceil(2 * log2Binomial(over, under))
+
+	return cost >= m
+}
+
+// log2Binomial computes log2(n choose k).
+func log2Binomial(n, k float64) float64 {
+	a, _ := math.Lgamma(n + 1)
+	b, _ := math.Lgamma(k + 1)
+	c, _ := math.Lgamma(n - k + 1)
+	return (a - b - c) / math.Ln2
+}
@@ -1,9 +1,11 @@
 package poseidon
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,6 +62,18 @@ func TestCalcRoundNum(t *testing.T) {
 	}
 }
 
+func TestCalcRoundNumGoldilocks(t *testing.T) {
+	// goldilocks uses x^7 since gcd(5, p-1) != 1, and t = 12 gives the round numbers used by plonky2.
+	assert.Equal(t, 7, sboxAlpha[*goldilocks.Element]())
+
+	tests := []struct{ t, rf, rp int }{{4, 8, 21}, {8, 8, 22}, {12, 8, 22}, {16, 8, 22}}
+	for _, cases := range tests {
+		rf, rp := calcRoundNumbers[*goldilocks.Element](cases.t, true)
+		assert.Equal(t, cases.rf, rf)
+		assert.Equal(t, cases.rp, rp)
+	}
+}
+
 func TestGenRoundConstants(t *testing.T) {
 	tests := []struct {
 		t, rf, rp int
@@ -100,3 +114,30 @@ func TestGenCompressedRoundConstants(t *testing.T) {
 		assert.Equal(t, len(comRoundContantsm), cases.want)
 	}
 }
+
+func TestUncompressRoundConstants(t *testing.T) {
+	for _, width := range []int{3, 5} {
+		want, err := GenPoseidonConstants[*fr.Element](width)
+		assert.NoError(t, err)
+
+		constants, err := uncompressRoundConstants(width, want.FullRounds, want.PartialRounds, want.CompRoundConsts, want.Mds.m)
+		assert.NoError(t, err)
+
+		// the constants are different, but they compress to the same constants.
+		get, err := newPoseidonConstants(width, want.FullRounds, want.PartialRounds, constants, want.Mds.m)
+		assert.NoError(t, err)
+		assert.False(t, IsVecEqual(want.RoundConsts, get.RoundConsts))
+		assert.Equal(t, want.CompRoundConsts, get.CompRoundConsts)
+		assert.NoError(t, get.Verify())
+
+		input := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}[:width]
+		h1, err := Permute(input, want)
+		assert.NoError(t, err)
+		h2, err := Permute(input, get)
+		assert.NoError(t, err)
+		assert.Equal(t, h1, h2)
+	}
+
+	_, err := uncompressRoundConstants[*fr.Element](3, 8, 57, nil, nil)
+	assert.Error(t, err)
+}
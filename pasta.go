@@ -0,0 +1,26 @@
+package poseidon
+
+import "math/big"
+
+// the pasta fields, pallas and vesta form a cycle of curves: the base field of each curve is the scalar field of the other.
+var (
+	// pastaFpModulus is 2^254 + 45560315531419706090280762371685220353, the base field of pallas.
+	pastaFpModulus, _ = new(big.Int).SetString("40000000000000000000000000000000224698fc094cf91b992d30ed00000001", 16)
+	// pastaFqModulus is 2^254 + 45560315531506369815346746415080538113, the base field of vesta.
+	pastaFqModulus, _ = new(big.Int).SetString("40000000000000000000000000000000224698fc0994a8dd8c46eb2100000001", 16)
+)
+
+// PastaFp is an element of the base field of pallas (the scalar field of vesta),
+// which is the native field of mina (kimchi) and the base field hashed by zcash orchard.
+type PastaFp = BigField[pastaFpParams]
+
+// PastaFq is an element of the base field of vesta (the scalar field of pallas).
+type PastaFq = BigField[pastaFqParams]
+
+type pastaFpParams struct{}
+
+func (pastaFpParams) Modulus() *big.Int { return pastaFpModulus }
+
+type pastaFqParams struct{}
+
+func (pastaFqParams) Modulus() *big.Int { return pastaFqModulus }
@@ -0,0 +1,217 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// goldilocksModulus is 2^64 - 2^32 + 1.
+const goldilocksModulus uint64 = 0xffffffff00000001
+
+// the parameters of plonky2's poseidon over goldilocks, the state is 8 rate elements followed by 4 capacity elements.
+const (
+	plonky2Width         = 12
+	plonky2Rate          = 8
+	plonky2FullRounds    = 8
+	plonky2PartialRounds = 22
+)
+
+// the mds matrix of plonky2 is circ(plonky2MdsCirc) + diag(plonky2MdsDiag).
+var (
+	plonky2MdsCirc = [plonky2Width]uint64{17, 15, 41, 16, 2, 28, 13, 13, 39, 18, 34, 20}
+	plonky2MdsDiag = [plonky2Width]uint64{8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+)
+
+// plonky2CompRoundConsts are the compressed round constants of plonky2's poseidon,
+// as published by go-iden3-crypto's goldenposeidon (the same permutation), see genCompressedRoundConstants.
+var plonky2CompRoundConsts = []uint64{
+	0xb585f766f2144405, 0x7746a55f43921ad7, 0xb2fb0d31cee799b4, 0x0f6760a4803427d7,
+	0xe10d666650f4e012, 0x8cae14cb07d09bf1, 0xd438539c95f63e9f, 0xef781c7ce35b4c3d,
+	0xcdc4a239b0c44426, 0x277fa208bf337bff, 0xe17653a29da578a1, 0xc54302f225db2c76,
+	0xac6c9c2b4418dd61, 0xe0888eb1e8a01286, 0x813dbe952b98904e, 0xcc3033609c9cf175,
+	0x72cebc82a59c0f82, 0x8150d8525753e741, 0xb1122c74b268d66e, 0x07c6ddd482375aa2,
+	0xa4dd6f1ef49fb6af, 0xd33b0d5b4f7ccfe5, 0xc523112247209124, 0x464804200134c32d,
+	0xcd09dea180de4f2c, 0xadb069225c93e4e6, 0xbf01209b8a7c8534, 0xb1eb37d319913823,
+	0xdadf943b8d3e5a0d, 0x6d15f3cb7a3520ba, 0xf07af62b134ef181, 0x568355076c6b0de6,
+	0x31ca4bf93cab68b8, 0x0fbad37a125735ba, 0x9d3a9caaf1ac9e0a, 0x4f265810f020c095,
+	0x6a84c9524e81a8bc, 0x68ba410537925c79, 0x422604631b34b07a, 0x28e3a001f62f8290,
+	0x3adfdccb8f734d41, 0x73503e539baec66a, 0xe8c1fd0142d9849c, 0xe204ac13660546c5,
+	0x8e2bb3ea97a40c53, 0xac2800d1bf56548c, 0x9494dca005d180d0, 0xf36e1d066383ef53,
+	0x8aa35b97a0e03c04, 0xcf42a59addbd1f0c, 0xa43ace89f8fdbd79, 0x037585d8c243870c,
+	0x4ab94ee3e26596fe, 0xcee3abbb50d57b23, 0xac91a7101a5ec55b, 0x9173aa8462280d2d,
+	0xaec1ca46ccb95105, 0x57b2f2845db61e4a, 0x95704158500c90c6, 0x66e023b0e6c9df5f,
+	0x315f63f4fec360ba, 0xf3009795713abcf1, 0xf4decc3fb00765ee, 0x32620ac918682d50,
+	0x49717d63a5fc742e, 0x153516f22014ea2d, 0xcc316380a2761fe4, 0x2e49b3f7076d203d,
+	0x44ac3e9bf0a2dc89, 0x0049d1e388d8e35c, 0x53ec867cb39989fa, 0xd2c9bcc8d65f5a62,
+	0xc0cc930ee8540455, 0x040651e0872505e8, 0x168973b2ebafbe6c, 0x9c7eecb3b40581c2,
+	0x389473bcdfca97a2, 0xb1cb0b3abe9753ad, 0x41afceccffdb18e6, 0x7bf841e237ccd6c9,
+	0x06082a3f101fb888, 0x8c1a39196f4163cc, 0xb56664760c1c9476, 0x2a02ac020d1eb5a3,
+	0x6a9d48e8aa83605d, 0x8a0d2f5c4c9c51b2, 0x75fc65575b284ad4, 0xadaedf7d1ce2a8dd,
+	0x235bc889cc83968e, 0xa8c30cf1781738f5, 0x546b2a846753bcf8, 0x9b68e8c06c04bd25,
+	0x3fdf80794ebb443b, 0x92ca132a9bec5a45, 0x76133eecfd9bd1ff, 0x3fb0fd5381054812,
+	0xf15925978dbd52ff, 0x2ee289ac37f0e879, 0xd8af8654e9a2e659, 0x8595bbd7f34c5e8a,
+	0x0206ddbf781e47b2, 0xe101a767854a2f97, 0xf4d4f0a01072c996, 0x197aec2894aab642,
+	0x8d0c3911220db49b, 0xa62a8bad609227ca, 0x1e4813a7e7b9cbce, 0x6b547528731244eb,
+	0xd08e48512bfea84e, 0xb2920c88d3885857, 0x1f0cd5d7a309fcc2, 0x99a0ea0842fdb4fb,
+	0xc227210554b6c53d, 0x70e5269708f6f3a9, 0xbe8f71c8c98bb3bd, 0xf96fb39adc4baaf6,
+	0x7f9a7555c60fc6c7, 0xccaa5446d71fe6a5,
+}
+
+var (
+	plonky2Once  sync.Once
+	plonky2Cons  *PoseidonConst[*goldilocks.Element]
+	plonky2Perm  *SPN[*goldilocks.Element]
+	plonky2Error error
+)
+
+// GenPlonky2Constants returns the constants of plonky2's poseidon (width 12, rf=8, rp=22, x^7) over goldilocks,
+// the round constants are derived from the compressed round constants, so they are equivalent to plonky2's
+// but not the same, see uncompressRoundConstants.
+func GenPlonky2Constants() (*PoseidonConst[*goldilocks.Element], error) {
+	plonky2Once.Do(func() {
+		mds := make(Matrix[*goldilocks.Element], plonky2Width)
+		for i := 0; i < plonky2Width; i++ {
+			mds[i] = make([]*goldilocks.Element, plonky2Width)
+			for j := 0; j < plonky2Width; j++ {
+				// state*M, the column r is the coefficients of the output r.
+				mds[i][j] = new(goldilocks.Element).SetUint64(plonky2MdsCirc[(i-j+plonky2Width)%plonky2Width])
+			}
+			mds[i][i].SetUint64(plonky2MdsCirc[0] + plonky2MdsDiag[i])
+		}
+
+		compressed := make([]*goldilocks.Element, len(plonky2CompRoundConsts))
+		for i, c := range plonky2CompRoundConsts {
+			compressed[i] = new(goldilocks.Element).SetUint64(c)
+		}
+
+		constants, err := uncompressRoundConstants(plonky2Width, plonky2FullRounds, plonky2PartialRounds, compressed, mds)
+		if err != nil {
+			plonky2Error = fmt.Errorf("uncompress round constants err: %w", err)
+			return
+		}
+
+		plonky2Cons, plonky2Error = newPoseidonConstants(plonky2Width, plonky2FullRounds, plonky2PartialRounds, constants, mds)
+		if plonky2Error == nil {
+			plonky2Perm = plonky2SPN(plonky2Cons)
+		}
+	})
+
+	return plonky2Cons, plonky2Error
+}
+
+// plonky2SPN returns the network of the static hash mode, where the full rounds multiply the small coefficients
+// of the mds matrix instead of the dense product.
+func plonky2SPN(c *PoseidonConst[*goldilocks.Element]) *SPN[*goldilocks.Element] {
+	// the cached network is shared, so the linear layer is replaced in a copy.
+	spn := *c.staticSPN()
+	static := spn.Linear
+	spn.Linear = LinearLayerFunc[*goldilocks.Element](func(dst, state []*goldilocks.Element, r int, tmp *goldilocks.Element) {
+		if r >= c.HalfFullRounds-1 && r < c.HalfFullRounds+c.PartialRounds {
+			// the pre-sparse and sparse matrices.
+			static.Apply(dst, state, r, tmp)
+			return
+		}
+
+		plonky2MdsLayer(dst, state)
+	})
+
+	return &spn
+}
+
+// plonky2MdsLayer computes dst[r] = sum_i circ[i]*state[(i+r) % 12] + diag[r]*state[r] in 128 bits before the reduction,
+// the elements are in the montgomery form, which is kept by the multiplication of the integer coefficients.
+func plonky2MdsLayer(dst, state []*goldilocks.Element) {
+	for r := 0; r < plonky2Width; r++ {
+		var hi, lo, carry uint64
+		for i := 0; i < plonky2Width; i++ {
+			h, l := bits.Mul64(state[(i+r)%plonky2Width][0], plonky2MdsCirc[i])
+			lo, carry = bits.Add64(lo, l, 0)
+			hi += h + carry
+		}
+
+		h, l := bits.Mul64(state[r][0], plonky2MdsDiag[r])
+		lo, carry = bits.Add64(lo, l, 0)
+		hi += h + carry
+
+		dst[r][0] = bits.Rem64(hi, lo, goldilocksModulus)
+	}
+}
+
+// plonky2Permute computes the permutation of the state in place.
+func plonky2Permute(state []*goldilocks.Element) error {
+	if _, err := GenPlonky2Constants(); err != nil {
+		return err
+	}
+
+	plonky2Perm.Permute(state, nil)
+	return nil
+}
+
+// Plonky2Permute applies plonky2's poseidon permutation to the 12 elements, the values are reduced modulo p.
+func Plonky2Permute(input [plonky2Width]uint64) ([plonky2Width]uint64, error) {
+	state := make([]*goldilocks.Element, plonky2Width)
+	for i := 0; i < plonky2Width; i++ {
+		state[i] = new(goldilocks.Element).SetUint64(input[i])
+	}
+
+	var out [plonky2Width]uint64
+	if err := plonky2Permute(state); err != nil {
+		return out, err
+	}
+
+	for i := 0; i < plonky2Width; i++ {
+		out[i] = state[i].Uint64()
+	}
+
+	return out, nil
+}
+
+// Plonky2HashNoPad computes plonky2's hash_no_pad, the inputs overwrite the rate elements 8 at a time
+// (a shorter last chunk keeps the rest of the state) and each chunk is followed by the permutation,
+// the output is the first 4 elements of the state, which is zero if there is no input.
+func Plonky2HashNoPad(inputs []uint64) ([4]uint64, error) {
+	state := newElements[*goldilocks.Element](plonky2Width)
+	for i := 0; i < len(inputs); i += plonky2Rate {
+		for j := i; j < i+plonky2Rate && j < len(inputs); j++ {
+			state[j-i].SetUint64(inputs[j])
+		}
+
+		if err := plonky2Permute(state); err != nil {
+			return [4]uint64{}, err
+		}
+	}
+
+	return [4]uint64{state[0].Uint64(), state[1].Uint64(), state[2].Uint64(), state[3].Uint64()}, nil
+}
+
+// Plonky2HashOrNoop computes plonky2's hash_or_noop, the inputs of at most 4 elements are padded with zeros
+// and returned without hashing, otherwise it is Plonky2HashNoPad.
+func Plonky2HashOrNoop(inputs []uint64) ([4]uint64, error) {
+	if len(inputs) <= 4 {
+		var out [4]uint64
+		for i, x := range inputs {
+			out[i] = new(goldilocks.Element).SetUint64(x).Uint64()
+		}
+
+		return out, nil
+	}
+
+	return Plonky2HashNoPad(inputs)
+}
+
+// Plonky2TwoToOne computes plonky2's two_to_one, that is, the first 4 elements of the permutation of (left, right, 0, 0, 0, 0).
+func Plonky2TwoToOne(left, right [4]uint64) ([4]uint64, error) {
+	var input [plonky2Width]uint64
+	copy(input[:4], left[:])
+	copy(input[4:8], right[:])
+
+	out, err := Plonky2Permute(input)
+	if err != nil {
+		return [4]uint64{}, err
+	}
+
+	return [4]uint64{out[0], out[1], out[2], out[3]}, nil
+}
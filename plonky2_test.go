@@ -0,0 +1,170 @@
+package poseidon
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlonky2Constants(t *testing.T) {
+	cons, err := GenPlonky2Constants()
+	assert.NoError(t, err)
+	assert.NoError(t, cons.Verify())
+	assert.Equal(t, big.NewInt(7), cons.Alpha)
+
+	// the pre-sparse and sparse matrices of goldenposeidon.
+	assert.Equal(t, uint64(0x19), cons.PreSparse[0][0].Uint64())
+	assert.Equal(t, uint64(0x78566230aa7cc5d0), cons.PreSparse[0][1].Uint64())
+	assert.Equal(t, uint64(0x817bd8a7869ed1b5), cons.PreSparse[11][11].Uint64())
+	assert.Equal(t, uint64(0x3d999c961b7c63b0), cons.Sparse[0].WHat[1].Uint64())
+	assert.Equal(t, uint64(0x3ee8011c2b37f77c), cons.Sparse[0].V[10].Uint64())
+	assert.Equal(t, uint64(0xf), cons.Sparse[21].V[10].Uint64())
+
+	// the small coefficients are the same as the dense product.
+	state := make([]*goldilocks.Element, plonky2Width)
+	for i := 0; i < plonky2Width; i++ {
+		state[i] = new(goldilocks.Element).SetUint64(rand.Uint64())
+	}
+
+	want := newElements[*goldilocks.Element](plonky2Width)
+	productMdsMatrix(want, state, cons.Mds.m, new(goldilocks.Element))
+	get := newElements[*goldilocks.Element](plonky2Width)
+	plonky2MdsLayer(get, state)
+	assert.Equal(t, want, get)
+}
+
+func TestPlonky2Permute(t *testing.T) {
+	const p = goldilocksModulus
+
+	fill := func(rate, capacity uint64) (s [plonky2Width]uint64) {
+		for i := 0; i < plonky2Width; i++ {
+			s[i] = rate
+			if i >= plonky2Rate {
+				s[i] = capacity
+			}
+		}
+		return s
+	}
+
+	// the test vectors of goldenposeidon, which are the first 4 elements of plonky2's permutation.
+	tests := []struct {
+		input [plonky2Width]uint64
+		want  [4]uint64
+	}{
+		{fill(0, 0), [4]uint64{4330397376401421145, 14124799381142128323, 8742572140681234676, 14345658006221440202}},
+		{fill(1, 1), [4]uint64{16428316519797902711, 13351830238340666928, 682362844289978626, 12150588177266359240}},
+		{fill(p-1, p-1), [4]uint64{13691089994624172887, 15662102337790434313, 14940024623104903507, 10772674582659927682}},
+		{fill(p, 0), [4]uint64{4330397376401421145, 14124799381142128323, 8742572140681234676, 14345658006221440202}},
+		{
+			[plonky2Width]uint64{923978, 235763497586, 9827635653498, 112870, 289273673480943876, 230295874986745876, 6254867324987, 2087},
+			[4]uint64{1892171027578617759, 984732815927439256, 7866041765487844082, 8161503938059336191},
+		},
+	}
+
+	cons, err := GenPlonky2Constants()
+	assert.NoError(t, err)
+
+	for _, c := range tests {
+		out, err := Plonky2Permute(c.input)
+		assert.NoError(t, err)
+		assert.Equal(t, c.want[:], out[:4])
+
+		// the same as the correct hash mode.
+		input := make([]*big.Int, plonky2Width)
+		for i := 0; i < plonky2Width; i++ {
+			input[i] = new(big.Int).SetUint64(c.input[i] % p)
+		}
+
+		want, err := Permute(input, cons)
+		assert.NoError(t, err)
+		for i := 0; i < plonky2Width; i++ {
+			assert.Equal(t, want[i].Uint64(), out[i])
+		}
+	}
+}
+
+func TestPlonky2PermuteVectors(t *testing.T) {
+	const p = goldilocksModulus
+
+	var seq, max [plonky2Width]uint64
+	for i := 0; i < plonky2Width; i++ {
+		seq[i] = uint64(i)
+		max[i] = p - 1
+	}
+
+	// the test vectors of plonky2 (plonky2/src/hash/poseidon_goldilocks.rs), all 12 elements are checked.
+	tests := []struct {
+		input [plonky2Width]uint64
+		want  [plonky2Width]uint64
+	}{
+		{
+			[plonky2Width]uint64{},
+			[plonky2Width]uint64{
+				0x3c18a9786cb0b359, 0xc4055e3364a246c3, 0x7953db0ab48808f4, 0xc71603f33a1144ca,
+				0xd7709673896996dc, 0x46a84e87642f44ed, 0xd032648251ee0b3c, 0x1c687363b207df62,
+				0xdf8565563e8045fe, 0x40f5b37ff4254dae, 0xd070f637b431067c, 0x1792b1c4342109d7,
+			},
+		},
+		{
+			seq,
+			[plonky2Width]uint64{
+				0xd64e1e3efc5b8e9e, 0x53666633020aaa47, 0xd40285597c6a8825, 0x613a4f81e81231d2,
+				0x414754bfebd051f0, 0xcb1f8980294a023f, 0x6eb2a9e4d54a9d0f, 0x1902bc3af467e056,
+				0xf045d5eafdc6021f, 0xe4150f77caaa3be5, 0xc9bfd01d39b50cce, 0x5c0a27fcb0e1459b,
+			},
+		},
+		{
+			max,
+			[plonky2Width]uint64{
+				0xbe0085cfc57a8357, 0xd95af71847d05c09, 0xcf55a13d33c1c953, 0x95803a74f4530e82,
+				0xfcd99eb30a135df1, 0xe095905e913a3029, 0xde0392461b42919b, 0x7d3260e24e81d031,
+				0x10d3d0465d9deaa0, 0xa87571083dfc2a47, 0xe18263681e9958f8, 0xe28e96f1ae5e60d3,
+			},
+		},
+	}
+
+	for _, c := range tests {
+		out, err := Plonky2Permute(c.input)
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, out)
+	}
+}
+
+func TestPlonky2Hash(t *testing.T) {
+	inputs := []uint64{923978, 235763497586, 9827635653498, 112870, 289273673480943876, 230295874986745876, 6254867324987, 2087}
+	want := [4]uint64{1892171027578617759, 984732815927439256, 7866041765487844082, 8161503938059336191}
+
+	h, err := Plonky2HashNoPad(inputs)
+	assert.NoError(t, err)
+	assert.Equal(t, want, h)
+
+	h, err = Plonky2HashOrNoop(inputs)
+	assert.NoError(t, err)
+	assert.Equal(t, want, h)
+
+	h, err = Plonky2TwoToOne([4]uint64{inputs[0], inputs[1], inputs[2], inputs[3]}, [4]uint64{inputs[4], inputs[5], inputs[6], inputs[7]})
+	assert.NoError(t, err)
+	assert.Equal(t, want, h)
+
+	// no hashing for at most 4 elements.
+	h, err = Plonky2HashOrNoop([]uint64{1, 2, goldilocksModulus})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{1, 2, 0, 0}, h)
+
+	h, err = Plonky2HashNoPad(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{}, h)
+
+	// the last chunk overwrites the first rate elements and keeps the others.
+	first, err := Plonky2Permute([plonky2Width]uint64{1, 2, 3, 4, 5, 6, 7, 8})
+	assert.NoError(t, err)
+	first[0] = 9
+	second, err := Plonky2Permute(first)
+	assert.NoError(t, err)
+	h, err = Plonky2HashNoPad([]uint64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	assert.NoError(t, err)
+	assert.Equal(t, second[:4], h[:])
+}
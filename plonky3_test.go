@@ -0,0 +1,178 @@
+package poseidon
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlonky3Poseidon2Permute(t *testing.T) {
+	// the test vectors of gnark-crypto, which are generated by plonky3 (commit de2b3b7)
+	// with default_babybear_poseidon2_16/24 and random inputs.
+	for _, width := range []int{16, 24} {
+		cons, err := GenPlonky3Poseidon2Constants(width)
+		assert.NoError(t, err)
+		assert.Equal(t, Plonky3M4, cons.M4)
+
+		f, err := os.Open(fmt.Sprintf("./data/poseidon2_babybear_%d_test_vectors.csv", width))
+		assert.NoError(t, err)
+		records, err := csv.NewReader(f).ReadAll()
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+
+		// skip the header.
+		assert.Equal(t, 21, len(records))
+		for _, record := range records[1:] {
+			values := make([]*big.Int, 2*width)
+			for i := range values {
+				v, ok := new(big.Int).SetString(record[i], 10)
+				assert.True(t, ok)
+				values[i] = v
+			}
+
+			out, err := Poseidon2Permute(values[:width], cons)
+			assert.NoError(t, err)
+			assert.Equal(t, values[width:], out)
+		}
+	}
+
+	_, err := GenPlonky3Poseidon2Constants(8)
+	assert.Error(t, err)
+}
+
+func TestPlonky3InternalDiag(t *testing.T) {
+	cons, err := GenPlonky3Poseidon2Constants(16)
+	assert.NoError(t, err)
+
+	// -2, 1/2 and -1/2^27.
+	two := new(BabyBear).SetUint64(2)
+	assert.True(t, new(BabyBear).Add(cons.InternalDiag[0], two).IsZero())
+	assert.True(t, new(BabyBear).Mul(cons.InternalDiag[3], two).Equal(one[*BabyBear]()))
+	x := new(BabyBear).SetUint64(1 << 27)
+	assert.True(t, x.Mul(x, cons.InternalDiag[15]).Equal(new(BabyBear).Neg(one[*BabyBear]())))
+}
+
+func TestPaddingFreeSponge(t *testing.T) {
+	cons, err := GenPlonky3Poseidon2Constants(16)
+	assert.NoError(t, err)
+
+	input := make([]*big.Int, 11)
+	for i := range input {
+		input[i] = big.NewInt(int64(i + 1))
+	}
+
+	// the first chunk fills the rate, and the second one overwrites the first 3 elements.
+	state := make([]*big.Int, 16)
+	for i := range state {
+		state[i] = big.NewInt(0)
+	}
+	copy(state, input[:8])
+	state, err = Poseidon2Permute(state, cons)
+	assert.NoError(t, err)
+	copy(state, input[8:])
+	state, err = Poseidon2Permute(state, cons)
+	assert.NoError(t, err)
+
+	h, err := PaddingFreeSponge(input, 8, 8, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, state[:8], h)
+
+	// the empty input is not permuted.
+	h, err = PaddingFreeSponge(nil, 8, 8, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(h))
+	for _, x := range h {
+		assert.Equal(t, 0, x.Sign())
+	}
+
+	_, err = PaddingFreeSponge(input, 17, 8, cons)
+	assert.Error(t, err)
+	_, err = PaddingFreeSponge([]*big.Int{big.NewInt(int64(BabyBearModulus))}, 8, 8, cons)
+	assert.Error(t, err)
+}
+
+func TestTruncatedPermutation(t *testing.T) {
+	cons, err := GenPlonky3Poseidon2Constants(16)
+	assert.NoError(t, err)
+
+	left := make([]*big.Int, 8)
+	right := make([]*big.Int, 8)
+	for i := 0; i < 8; i++ {
+		left[i] = big.NewInt(int64(i))
+		right[i] = big.NewInt(int64(i + 8))
+	}
+
+	out, err := Poseidon2Permute(append(append([]*big.Int{}, left...), right...), cons)
+	assert.NoError(t, err)
+	h, err := TruncatedPermutation([][]*big.Int{left, right}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[:8], h)
+
+	_, err = TruncatedPermutation([][]*big.Int{left, right[:7]}, cons)
+	assert.Error(t, err)
+	_, err = TruncatedPermutation([][]*big.Int{left, right, left}, cons)
+	assert.Error(t, err)
+}
+
+func TestRiscZeroHash(t *testing.T) {
+	// the internal diagonal of risc zero is not embedded, any invertible internal matrix checks the sponge.
+	diag := make([]*BabyBear, 24)
+	for i := range diag {
+		diag[i] = new(BabyBear).SetUint64(uint64(i + 1))
+	}
+
+	cons, err := GenRiscZeroPoseidon2Constants(diag)
+	assert.NoError(t, err)
+	assert.Equal(t, HorizenM4, cons.M4)
+	assert.Equal(t, 21, cons.PartialRounds)
+
+	zero := func(n int) []*big.Int {
+		z := make([]*big.Int, n)
+		for i := range z {
+			z[i] = big.NewInt(0)
+		}
+		return z
+	}
+
+	// the empty input permutes the zero state once.
+	out, err := Poseidon2Permute(zero(24), cons)
+	assert.NoError(t, err)
+	h, err := RiscZeroHash(nil, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[:8], h)
+
+	// 17 elements, the last chunk is padded with zeros.
+	input := make([]*big.Int, 17)
+	for i := range input {
+		input[i] = big.NewInt(int64(i + 1))
+	}
+
+	state := zero(24)
+	copy(state, input[:16])
+	state, err = Poseidon2Permute(state, cons)
+	assert.NoError(t, err)
+	copy(state, append(input[16:], zero(15)...))
+	state, err = Poseidon2Permute(state, cons)
+	assert.NoError(t, err)
+
+	h, err = RiscZeroHash(input, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, state[:8], h)
+
+	// hash_pair.
+	state = append(append(input[:8:8], input[8:16]...), zero(8)...)
+	out, err = Poseidon2Permute(state, cons)
+	assert.NoError(t, err)
+	h, err = RiscZeroHashPair(input[:8], input[8:16], cons)
+	assert.NoError(t, err)
+	assert.Equal(t, out[:8], h)
+
+	_, err = RiscZeroHashPair(input[:8], input[8:], cons)
+	assert.Error(t, err)
+	_, err = GenRiscZeroPoseidon2Constants(diag[:16])
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package poseidon
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 )
@@ -14,6 +15,12 @@ type PoseidonConst[E Element[E]] struct {
 	FullRounds      int
 	HalfFullRounds  int
 	PartialRounds   int
+	// Alpha is the exponent used in the sbox, PoseidonExp is used if it is nil.
+	Alpha *big.Int
+
+	// the networks of the hash modes, which are built once with the constants, see buildSPNs,
+	// so the constants should not be replaced after the creation, Verify reports the stale networks.
+	static, dynamic, correct *SPN[E]
 }
 
 // provide three hash modes.
@@ -31,6 +38,15 @@ const (
 // exponent used in the sbox.
 var PoseidonExp = new(big.Int).SetUint64(5)
 
+// alpha returns the exponent used in the sbox.
+func (c *PoseidonConst[E]) alpha() *big.Int {
+	if c.Alpha == nil {
+		return PoseidonExp
+	}
+
+	return c.Alpha
+}
+
 // Hash implements poseidon hash in this paper: https://eprint.iacr.org/2019/458.pdf.
 // we refer the rust implement (OptimizedStatic mode), see https://github.com/filecoin-project/neptune.
 // the input length is a slice of big integers.
@@ -64,6 +80,15 @@ func Hash[E Element[E]](input []*big.Int, pdsContants *PoseidonConst[E], hash Ha
 
 // generate poseidon constants used in the poseidon hash.
 func GenPoseidonConstants[E Element[E]](width int) (*PoseidonConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	// the state should be large enough for the security level, which matters for small fields.
+	if Bits[E]()*width < 2*SecurityLevel {
+		return nil, fmt.Errorf("width %d is too small for the %d-bit field", width, Bits[E]())
+	}
+
 	// round numbers.
 	rf, rp := calcRoundNumbers[E](width, true)
 	if rf%2 != 0 {
@@ -77,10 +102,55 @@ func GenPoseidonConstants[E Element[E]](width int) (*PoseidonConst[E], error) {
 }
 
 func GenCustomPoseidonConstants[E Element[E]](width, field, sbox, rf, rp int, mds Matrix[E]) (*PoseidonConst[E], error) {
-	half := rf / 2
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
 
 	constants := genRoundConstants[E](field, sbox, Bits[E](), width, rf, rp)
 
+	return newPoseidonConstants(width, rf, rp, constants, mds)
+}
+
+// GenReferenceConstants generates the poseidon constants as in the reference script (https://extgit.iaik.tugraz.at/krypto/hadeshash)
+// with the given round numbers, both the round constants and the mds matrix are sampled from the same grain lfsr,
+// these are the constants used by circomlib.
+func GenReferenceConstants[E Element[E]](width, rf, rp int) (*PoseidonConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	if err := checkRoundNumbers(rf, rp); err != nil {
+		return nil, err
+	}
+
+	grain := NewGrainLFSR[E](1, 0, Bits[E](), width, rf, rp)
+	constants := make([]E, (rf+rp)*width)
+	for i := 0; i < len(constants); i++ {
+		constants[i] = grain.NextFieldElement()
+	}
+
+	mds := genReferenceMDS(grain, width)
+
+	return newPoseidonConstants(width, rf, rp, constants, mds)
+}
+
+// newPoseidonConstants derives the mds matrices, the compressed round constants,
+// sparse and pre-sparse matrices from the given round constants and mds matrix.
+func newPoseidonConstants[E Element[E]](width, rf, rp int, constants []E, mds Matrix[E]) (*PoseidonConst[E], error) {
+	if err := checkRoundNumbers(rf, rp); err != nil {
+		return nil, err
+	}
+
+	half := rf / 2
+
+	if len(constants) != (rf+rp)*width {
+		return nil, fmt.Errorf("round constants length %d is inconsistent, want %d", len(constants), (rf+rp)*width)
+	}
+
+	if !IsSquareMatrix(mds) || row(mds) != width {
+		return nil, fmt.Errorf("mds matrix should be a %d*%d matrix", width, width)
+	}
+
 	// mds matrices.
 	mdsm, err := deriveMatrices(mds)
 	if err != nil {
@@ -99,7 +169,7 @@ func GenCustomPoseidonConstants[E Element[E]](width, field, sbox, rf, rp int, md
 		return nil, fmt.Errorf("generate sparse matrix err: %w", err)
 	}
 
-	return &PoseidonConst[E]{
+	c := &PoseidonConst[E]{
 		Mds:             mdsm,
 		RoundConsts:     constants,
 		CompRoundConsts: compress,
@@ -108,84 +178,161 @@ func GenCustomPoseidonConstants[E Element[E]](width, field, sbox, rf, rp int, md
 		FullRounds:      rf,
 		PartialRounds:   rp,
 		HalfFullRounds:  half,
-	}, nil
+		Alpha:           big.NewInt(int64(sboxAlpha[E]())),
+	}
+
+	if err := c.buildSPNs(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
 }
 
-func optimizedStaticHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
-	t := len(state)
-	// The first full round should use the initial constants.
-	for i := 0; i < t; i++ {
-		state[i].Add(state[i], pdsConsts.CompRoundConsts[i])
+// checkRoundNumbers checks the round numbers, the full rounds are split into two halves around the partial rounds,
+// so they should be even unless there is no partial round (e.g. the 55 full rounds of kimchi).
+func checkRoundNumbers(rf, rp int) error {
+	if rf < 2 || rp < 0 {
+		return fmt.Errorf("round numbers rf=%d, rp=%d are invalid", rf, rp)
 	}
 
-	// do the first half full rounds
-	for i := 0; i < pdsConsts.HalfFullRounds; i++ {
-		state = staticFullRounds(state, false, i*t+t, pdsConsts)
+	if rp > 0 && rf%2 != 0 {
+		return fmt.Errorf("full rounds %d should be even", rf)
 	}
 
-	// do the partial rounds
-	for i := 0; i < pdsConsts.PartialRounds; i++ {
-		state = staticPartialRounds(state, i+pdsConsts.HalfFullRounds*t+t, pdsConsts)
+	return nil
+}
+
+// Verify checks that the pre-computed constants (compressed round constants, sparse and pre-sparse matrices)
+// are consistent with the round constants and the mds matrix,
+// it is useful when the constants are loaded from disk or others.
+func (c *PoseidonConst[E]) Verify() error {
+	if c.Mds == nil || !IsSquareMatrix(c.Mds.m) || row(c.Mds.m) < 2 {
+		return errors.New("mds matrix is invalid")
 	}
+	width := row(c.Mds.m)
 
-	// do the final full rounds
-	for i := 0; i < pdsConsts.HalfFullRounds-1; i++ {
-		state = staticFullRounds(state, false, i*t+pdsConsts.HalfFullRounds*t+pdsConsts.PartialRounds+t, pdsConsts)
+	// round numbers.
+	if err := checkRoundNumbers(c.FullRounds, c.PartialRounds); err != nil {
+		return err
 	}
 
-	// last round
-	state = staticFullRounds(state, true, -1, pdsConsts)
+	if c.HalfFullRounds != c.FullRounds/2 {
+		return fmt.Errorf("full rounds %d and half full rounds %d are inconsistent", c.FullRounds, c.HalfFullRounds)
+	}
 
-	// output state[1]
-	h := new(big.Int)
-	state[1].BigInt(h)
+	if len(c.RoundConsts) != (c.FullRounds+c.PartialRounds)*width {
+		return fmt.Errorf("round constants length %d is inconsistent, want %d",
+			len(c.RoundConsts), (c.FullRounds+c.PartialRounds)*width)
+	}
 
-	return h, nil
-}
+	if len(c.CompRoundConsts) != c.FullRounds*width+c.PartialRounds {
+		return fmt.Errorf("compressed round constants length %d is inconsistent, want %d",
+			len(c.CompRoundConsts), c.FullRounds*width+c.PartialRounds)
+	}
 
-func optimizedDynamicHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
-	t := len(state)
-	// The first full round should use the initial constants.
-	state = dynamicFullRounds(state, true, true, 0, pdsConsts)
+	if len(c.Sparse) != c.PartialRounds {
+		return fmt.Errorf("sparse matrices length %d is inconsistent, want %d", len(c.Sparse), c.PartialRounds)
+	}
 
-	for i := 0; i < pdsConsts.HalfFullRounds-1; i++ {
-		state = dynamicFullRounds(state, false, true, (2+i)*t, pdsConsts)
+	if !IsSquareMatrix(c.PreSparse) || row(c.PreSparse) != width {
+		return errors.New("pre-sparse matrix is not a width*width matrix")
 	}
 
-	state = dynamicPartialRounds(state, pdsConsts)
-	for i := 1; i < pdsConsts.PartialRounds; i++ {
-		state = partialRounds(state, (pdsConsts.HalfFullRounds+i)*t, pdsConsts)
+	// mds matrices.
+	mds, err := deriveMatrices(c.Mds.m)
+	if err != nil {
+		return fmt.Errorf("derive mds matrices err: %w", err)
 	}
 
-	for i := 0; i < pdsConsts.HalfFullRounds; i++ {
-		state = dynamicFullRounds(state, true, false, (pdsConsts.HalfFullRounds+pdsConsts.PartialRounds+i)*t, pdsConsts)
+	if !IsEqual(mds.mInv, c.Mds.mInv) {
+		return errors.New("mds inverse matrix is inconsistent")
 	}
 
-	// output state[1]
-	h := new(big.Int)
-	state[1].BigInt(h)
+	if !IsEqual(mds.mHat, c.Mds.mHat) || !IsEqual(mds.mHatInv, c.Mds.mHatInv) {
+		return errors.New("mds mHat matrices are inconsistent")
+	}
 
-	return h, nil
-}
+	if !IsEqual(mds.mPrime, c.Mds.mPrime) || !IsEqual(mds.mDoublePrime, c.Mds.mDoublePrime) {
+		return errors.New("mds mPrime matrices are inconsistent")
+	}
 
-func correctHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
-	t := len(state)
+	// sparse matrices, recompose each sparse matrix m'' with m', it should end with the mds matrix,
+	// see genSparseMatrix.
+	acc := copyMatrixRows(c.PreSparse, 0, width)
+	for i := 0; i < c.PartialRounds; i++ {
+		s, err := c.Sparse[i].matrix(width)
+		if err != nil {
+			return fmt.Errorf("sparse matrix %d err: %w", i, err)
+		}
+
+		prime, err := MatMul(c.Mds.mInv, acc)
+		if err != nil {
+			return fmt.Errorf("sparse matrix %d err: %w", i, err)
+		}
+
+		if !IsEqual(prime, genPrime(prime)) {
+			return fmt.Errorf("sparse matrix %d is inconsistent with pre-sparse matrix", i)
+		}
+
+		acc, err = MatMul(prime, s)
+		if err != nil {
+			return fmt.Errorf("sparse matrix %d err: %w", i, err)
+		}
+	}
+
+	if !IsEqual(acc, c.Mds.m) {
+		return errors.New("sparse matrices are inconsistent with the mds matrix")
+	}
 
-	// do the first half full rounds.
-	for i := 0; i < pdsConsts.HalfFullRounds; i++ {
-		state = fullRounds(state, i*t, pdsConsts)
+	// compressed round constants.
+	compress, err := genCompressedRoundConstants(width, c.FullRounds, c.PartialRounds, c.RoundConsts, mds)
+	if err != nil {
+		return fmt.Errorf("generate compressed round constants err: %w", err)
 	}
 
-	// do the partial rounds.
-	for i := 0; i < pdsConsts.PartialRounds; i++ {
-		state = partialRounds(state, (pdsConsts.HalfFullRounds+i)*t, pdsConsts)
+	if !IsVecEqual(compress, c.CompRoundConsts) {
+		return errors.New("compressed round constants are inconsistent")
 	}
 
-	// do the final full rounds.
-	for i := 0; i < pdsConsts.HalfFullRounds; i++ {
-		state = fullRounds(state, (pdsConsts.HalfFullRounds+pdsConsts.PartialRounds+i)*t, pdsConsts)
+	// hash a fixed input in all hash modes, the networks are rebuilt from the constants,
+	// then the cached networks should give the same hash.
+	input := func() []E {
+		state := make([]E, width)
+		state[0] = NewElement[E]().SetUint64(hashDomainTag)
+		for i := 1; i < width; i++ {
+			state[i] = NewElement[E]().SetUint64(uint64(i - 1))
+		}
+		return state
 	}
 
+	dynamic, err := c.newDynamicSPN()
+	if err != nil {
+		return fmt.Errorf("hash mode %d err: %w", OptimizedDynamic, err)
+	}
+
+	fresh := []*SPN[E]{c.newStaticSPN(), dynamic, c.newCorrectSPN()}
+	cached := []*SPN[E]{c.static, c.dynamic, c.correct}
+
+	var h E
+	for mode := range fresh {
+		get := fresh[mode].Permute(input(), nil)[hashOutputIndex]
+		if mode == 0 {
+			h = get
+		} else if !h.Equal(get) {
+			return fmt.Errorf("hash mode %d is inconsistent with the other hash modes", mode)
+		}
+
+		if cached[mode] != nil && !h.Equal(cached[mode].Permute(input(), nil)[hashOutputIndex]) {
+			return fmt.Errorf("hash mode %d is inconsistent with the constants, they are modified after the creation", mode)
+		}
+	}
+
+	return nil
+}
+
+func optimizedStaticHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
+	state = pdsConsts.staticSPN().Permute(state, nil)
+
 	// output state[1]
 	h := new(big.Int)
 	state[1].BigInt(h)
@@ -193,198 +340,231 @@ func correctHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int
 	return h, nil
 }
 
-// addRoundConsts adds round constants to the input.
-func addRoundConsts[E Element[E]](state []E, RoundConsts []E) []E {
-	for i := 0; i < len(state); i++ {
-		state[i].Add(state[i], RoundConsts[i])
+func optimizedDynamicHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
+	spn, err := pdsConsts.dynamicSPN()
+	if err != nil {
+		return nil, err
 	}
+	state = spn.Permute(state, nil)
+
+	// output state[1]
+	h := new(big.Int)
+	state[1].BigInt(h)
 
-	return state
+	return h, nil
 }
 
-// sbox computes x^5 mod p
-func sbox[E Element[E]](e E, pre, post *E) {
-	//if pre is not nil, add round constants before computing the sbox.
-	if pre != nil && !isNil(*pre) {
-		e.Add(e, *pre)
-	}
+func correctHash[E Element[E]](state []E, pdsConsts *PoseidonConst[E]) (*big.Int, error) {
+	state = permute(state, pdsConsts, nil)
 
-	x := NewElement[E]().Set(e)
-	Exp(e, x, PoseidonExp)
+	// output state[1]
+	h := new(big.Int)
+	state[1].BigInt(h)
 
-	// if post is not nil, add round constants after computing the sbox.
-	if post != nil && !isNil(*post) {
-		e.Add(e, *post)
+	return h, nil
+}
+
+// Permute applies the poseidon permutation to the whole state,
+// the width of the state should be the same as the width of the constants.
+// unlike Hash, the domain tag is not added, and the whole output state is returned.
+func Permute[E Element[E]](input []*big.Int, pdsConsts *PoseidonConst[E]) ([]*big.Int, error) {
+	if len(input) != row(pdsConsts.Mds.m) {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), row(pdsConsts.Mds.m))
 	}
+
+	state := permute(bigToElement[E](input), pdsConsts, nil)
+
+	return elementToBig(state), nil
 }
 
-// staticPartialRounds computes arc->sbox->M, which has partial sbox layers,
-// see https://eprint.iacr.org/2019/458.pdf page 6.
-// The partial round is the same as the full round, with the difference
-// that we apply the S-Box only to the first element.
-func staticPartialRounds[E Element[E]](state []E, offset int, pdsConsts *PoseidonConst[E]) []E {
-	// swap the order of the linear layer and the round constant addition,
-	// see https://eprint.iacr.org/2019/458.pdf page 20.
-	sbox(state[0], nil, &pdsConsts.CompRoundConsts[offset])
-
-	state = productSparseMatrix(state, offset-len(state)*(pdsConsts.HalfFullRounds+1), pdsConsts.Sparse)
-	return state
+// buildSPNs builds the networks of the hash modes, so that each hash reuses them,
+// the networks share the round constants and the matrices, and Alpha is read in each permutation.
+func (c *PoseidonConst[E]) buildSPNs() error {
+	dynamic, err := c.newDynamicSPN()
+	if err != nil {
+		return err
+	}
+
+	c.static, c.dynamic, c.correct = c.newStaticSPN(), dynamic, c.newCorrectSPN()
+	return nil
 }
 
-// staticFullRounds computes arc->sbox->M, which has full sbox layers,
-// see https://eprint.iacr.org/2019/458.pdf page 6.
-func staticFullRounds[E Element[E]](state []E, lastRound bool, offset int, pdsConsts *PoseidonConst[E]) []E {
-	// in the last round, there is no need to add round constants because
-	// we have swapped the order of the linear layer and the round constant addition.
-	// see https://eprint.iacr.org/2019/458.pdf page 20.
-	if lastRound {
-		for i := 0; i < len(state); i++ {
-			sbox(state[i], nil, nil)
-		}
-	} else {
-		for i := 0; i < len(state); i++ {
-			postKey := pdsConsts.CompRoundConsts[offset+i]
-			sbox(state[i], nil, &postKey)
-		}
+// staticSPN returns the network of the static hash mode, it is built if the constants are not created
+// by this package (e.g. a struct literal).
+func (c *PoseidonConst[E]) staticSPN() *SPN[E] {
+	if c.static == nil {
+		return c.newStaticSPN()
 	}
 
-	// in the fourth full round, we should compute the product between the elements
-	// and the pre-sparse matrix (M*M'), see https://eprint.iacr.org/2019/458.pdf page 20.
-	if offset == 4*len(state) {
-		state = productPreSparseMatrix(state, pdsConsts.PreSparse)
-	} else {
-		state = productMdsMatrix(state, pdsConsts.Mds.m)
+	return c.static
+}
+
+// dynamicSPN returns the network of the dynamic hash mode.
+func (c *PoseidonConst[E]) dynamicSPN() (*SPN[E], error) {
+	if c.dynamic == nil {
+		return c.newDynamicSPN()
 	}
 
-	return state
+	return c.dynamic, nil
 }
 
-// dynamic partial rounds used in the dynamic hash mode.
-func dynamicPartialRounds[E Element[E]](state []E, pdsContants *PoseidonConst[E]) []E {
-	// sbox layer.
-	sbox(state[0], nil, nil)
+// correctSPN returns the network of the correct hash mode.
+func (c *PoseidonConst[E]) correctSPN() *SPN[E] {
+	if c.correct == nil {
+		return c.newCorrectSPN()
+	}
 
-	// mixed layer, multiply the elements by the constant MDS matrix.
-	state = productMdsMatrix(state, pdsContants.Mds.m)
+	return c.correct
+}
 
-	return state
+// permute computes the permutation in the correct hash mode,
+// if trace is not nil, it is called with the state after each round.
+func permute[E Element[E]](state []E, pdsConsts *PoseidonConst[E], trace func(state []E)) []E {
+	return pdsConsts.correctSPN().Permute(state, trace)
 }
 
-// dynamic full rounds used in the dynamic hash mode.
-func dynamicFullRounds[E Element[E]](state []E, current, next bool, offset int, pdsContants *PoseidonConst[E]) []E {
-	t := len(state)
-	preRoundKeys := make([]E, t)
-	postVec := make([]E, t)
+// newCorrectSPN creates the network of the correct hash mode,
+// each round computes ark->sbox->M, see https://eprint.iacr.org/2019/458.pdf page 6.
+func (c *PoseidonConst[E]) newCorrectSPN() *SPN[E] {
+	t := row(c.Mds.m)
+	rounds := c.FullRounds + c.PartialRounds
 
-	// if `current` is true, we need to add the round constants before the sbox layer.
-	if current {
-		copy(preRoundKeys, pdsContants.RoundConsts[offset:offset+t])
+	pre := make([][]E, rounds)
+	for r := 0; r < rounds; r++ {
+		pre[r] = c.RoundConsts[r*t : (r+1)*t]
 	}
 
-	// if `next` is true, we need to absorb the next round constants after the previous sbox layer.
-	if next {
-		if current {
-			copy(postVec, pdsContants.RoundConsts[offset+t:offset+2*t])
-		} else {
-			copy(postVec, pdsContants.RoundConsts[offset:offset+t])
-		}
+	return &SPN[E]{
+		Schedule:  HadesSchedule{FullRounds: c.FullRounds, PartialRounds: c.PartialRounds},
+		SBox:      poseidonSBox[E]{c},
+		Linear:    MdsLayer[E]{M: c.Mds.m},
+		PreConsts: pre,
+	}
+}
 
-		// M^-1(s)
-		inv, err := RightMatMul(postVec, pdsContants.Mds.mInv)
-		if err != nil {
-			panic(err)
+// newDynamicSPN creates the network of the dynamic hash mode,
+// the round constants of the next round are absorbed after the sbox layer of the first half full rounds,
+// that is, M(s + c) = M(s) + M(M^-1(c)), and the first partial round has no constants.
+func (c *PoseidonConst[E]) newDynamicSPN() (*SPN[E], error) {
+	t := row(c.Mds.m)
+	rounds := c.FullRounds + c.PartialRounds
+
+	pre := make([][]E, rounds)
+	post := make([][]E, c.HalfFullRounds)
+	for r := 0; r < rounds; r++ {
+		if r < c.HalfFullRounds {
+			// M^-1(c)
+			inv, err := RightMatMul(c.RoundConsts[(r+1)*t:(r+2)*t], c.Mds.mInv)
+			if err != nil {
+				return nil, fmt.Errorf("absorb round constants err: %w", err)
+			}
+			post[r] = inv
 		}
 
-		postRoundKeys := make([]E, t)
-		copy(postRoundKeys, inv)
-
-		// sbox layer.
-		for i := 0; i < t; i++ {
-			sbox(state[i], &preRoundKeys[i], &postRoundKeys[i])
-		}
-	} else {
-		// sbox layer.
-		for i := 0; i < t; i++ {
-			sbox(state[i], &preRoundKeys[i], nil)
+		if r == 0 || r > c.HalfFullRounds {
+			pre[r] = c.RoundConsts[r*t : (r+1)*t]
 		}
 	}
 
-	// mixed layer, multiply the elements by the constant MDS matrix.
-	state = productMdsMatrix(state, pdsContants.Mds.m)
-	return state
+	return &SPN[E]{
+		Schedule:   HadesSchedule{FullRounds: c.FullRounds, PartialRounds: c.PartialRounds},
+		SBox:       poseidonSBox[E]{c},
+		Linear:     MdsLayer[E]{M: c.Mds.m},
+		PreConsts:  pre,
+		PostConsts: post,
+	}, nil
 }
 
-// partial rounds used in the correct hash mode.
-func partialRounds[E Element[E]](state []E, offset int, pdsConsts *PoseidonConst[E]) []E {
-	// ark.
-	state = addRoundConsts(state, pdsConsts.RoundConsts[offset:offset+len(state)])
+// newStaticSPN creates the network of the static hash mode, which consumes the compressed round constants,
+// the order of the linear layer and the round constant addition is swapped,
+// the last full round of the first half uses the pre-sparse matrix (M*M'),
+// and the partial rounds use the sparse matrices, see https://eprint.iacr.org/2019/458.pdf page 20.
+func (c *PoseidonConst[E]) newStaticSPN() *SPN[E] {
+	t := row(c.Mds.m)
+	rounds := c.FullRounds + c.PartialRounds
+
+	// the first full round should use the initial constants,
+	// and there is no need to add round constants in the last round.
+	post := make([][]E, rounds-1)
+	offset := t
+	for r := 0; r < rounds-1; r++ {
+		n := t
+		if r >= c.HalfFullRounds && r < c.HalfFullRounds+c.PartialRounds {
+			n = 1
+		}
 
-	// sbox layer.
-	sbox(state[0], nil, nil)
+		post[r] = c.CompRoundConsts[offset : offset+n]
+		offset += n
+	}
 
-	// mixed layer, multiply the elements by the constant MDS matrix.
-	state = productMdsMatrix(state, pdsConsts.Mds.m)
+	return &SPN[E]{
+		Schedule:   HadesSchedule{FullRounds: c.FullRounds, PartialRounds: c.PartialRounds},
+		SBox:       poseidonSBox[E]{c},
+		Linear:     staticLinear[E]{c},
+		PreConsts:  [][]E{c.CompRoundConsts[:t]},
+		PostConsts: post,
+	}
+}
 
-	return state
+// poseidonSBox is the s-box layer x^Alpha, Alpha is read in each round since it may be set after the constants are created.
+type poseidonSBox[E Element[E]] struct {
+	c *PoseidonConst[E]
 }
 
-// full rounds used in the correct hash mode.
-func fullRounds[E Element[E]](state []E, offset int, pdsConsts *PoseidonConst[E]) []E {
-	// sbox layer.
-	for i := 0; i < len(state); i++ {
-		sbox(state[i], &pdsConsts.RoundConsts[offset+i], nil)
-	}
+func (s poseidonSBox[E]) Apply(state []E, r int, kind RoundKind, tmp E) {
+	PowerSBox[E]{Alpha: s.c.alpha()}.Apply(state, r, kind, tmp)
+}
 
-	// mixed layer, multiply the elements by the constant MDS matrix.
-	state = productMdsMatrix(state, pdsConsts.Mds.m)
+// staticLinear is the linear layer of the static hash mode, the last full round of the first half
+// uses the pre-sparse matrix, the partial rounds use the sparse matrices, and the others use the mds matrix.
+type staticLinear[E Element[E]] struct {
+	c *PoseidonConst[E]
+}
 
-	return state
+func (l staticLinear[E]) Apply(dst, state []E, r int, tmp E) {
+	c := l.c
+	switch {
+	case r == c.HalfFullRounds-1:
+		productPreSparseMatrix(dst, state, c.PreSparse, tmp)
+	case r >= c.HalfFullRounds && r < c.HalfFullRounds+c.PartialRounds:
+		productSparseMatrix(dst, state, r-c.HalfFullRounds, c.Sparse, tmp)
+	default:
+		productMdsMatrix(dst, state, c.Mds.m, tmp)
+	}
 }
 
-// productMdsMatrix computes the product between the elements and the mds matrix.
-func productMdsMatrix[E Element[E]](state []E, mds Matrix[E]) []E {
+// productMdsMatrix computes the product between the elements and the mds matrix, and writes it into dst.
+func productMdsMatrix[E Element[E]](dst, state []E, mds Matrix[E], tmp E) {
 	if len(state) != len(mds) {
 		panic("cannot compute the product !")
 	}
 
-	var res []E
 	for j := 0; j < len(state); j++ {
-		tmp1 := NewElement[E]()
+		dst[j].SetZero()
 		for i := 0; i < len(state); i++ {
-			tmp2 := NewElement[E]().Mul(state[i], mds[i][j])
-			tmp1.Add(tmp1, tmp2)
+			tmp.Mul(state[i], mds[i][j])
+			dst[j].Add(dst[j], tmp)
 		}
-		res = append(res, tmp1)
 	}
-
-	//res,err := RightMatMul(state,mds)
-	//if err != nil {
-	//	panic(err)
-	//}
-	return res
 }
 
-// productPreSparseMatrix computes the product between the elements and the pre-sparse matrix.
-func productPreSparseMatrix[E Element[E]](state []E, preSparseMatrix Matrix[E]) []E {
+// productPreSparseMatrix computes the product between the elements and the pre-sparse matrix, and writes it into dst.
+func productPreSparseMatrix[E Element[E]](dst, state []E, preSparseMatrix Matrix[E], tmp E) {
 	if len(state) != len(preSparseMatrix) {
 		panic("cannot compute the product !")
 	}
 
-	var res []E
 	for j := 0; j < len(state); j++ {
-		tmp1 := NewElement[E]()
+		dst[j].SetZero()
 		for i := 0; i < len(state); i++ {
-			tmp2 := NewElement[E]().Mul(state[i], preSparseMatrix[i][j])
-			tmp1.Add(tmp1, tmp2)
+			tmp.Mul(state[i], preSparseMatrix[i][j])
+			dst[j].Add(dst[j], tmp)
 		}
-		res = append(res, tmp1)
 	}
-
-	return res
 }
 
-// productSparseMatrix computes the product between the elements and the sparse matrix.
-func productSparseMatrix[E Element[E]](state []E, offset int, sparse []*SparseMatrix[E]) []E {
+// productSparseMatrix computes the product between the elements and the sparse matrix, and writes it into dst.
+func productSparseMatrix[E Element[E]](dst, state []E, offset int, sparse []*SparseMatrix[E], tmp E) {
 	// this part is described in https://eprint.iacr.org/2019/458.pdf page 20.
 	// the sparse matrix M'' consists of:
 	//
@@ -398,17 +578,14 @@ func productSparseMatrix[E Element[E]](state []E, offset int, sparse []*SparseMa
 	// we can first compute ret[0] = state * [M_00, w_hat],
 	// then for 1 <= i < t,
 	// compute ret[i] = state[0] * v[i-1] + state[i].
-	res := make([]E, len(state))
-	res[0] = NewElement[E]()
+	dst[0].SetZero()
 	for i := 0; i < len(state); i++ {
-		tmp := NewElement[E]().Mul(state[i], sparse[offset].WHat[i])
-		res[0].Add(res[0], tmp)
+		tmp.Mul(state[i], sparse[offset].WHat[i])
+		dst[0].Add(dst[0], tmp)
 	}
 
 	for i := 1; i < len(state); i++ {
-		tmp := NewElement[E]().Mul(state[0], sparse[offset].V[i-1])
-		res[i] = NewElement[E]().Add(state[i], tmp)
+		tmp.Mul(state[0], sparse[offset].V[i-1])
+		dst[i].Add(state[i], tmp)
 	}
-
-	return res
 }
@@ -0,0 +1,452 @@
+package poseidon
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Poseidon2Const is the constants used in the poseidon2 permutation,
+// see https://eprint.iacr.org/2023/323.pdf.
+// poseidon2 replaces the dense mds matrix with the cheap external and internal matrices.
+type Poseidon2Const[E Element[E]] struct {
+	// RoundConsts are (rf+rp)*t round constants, only the first constant of each partial round is used.
+	RoundConsts []E
+	// InternalDiag is the diagonal of the internal matrix minus one, that is,
+	// the internal matrix is diag(InternalDiag) + 1·1ᵀ.
+	InternalDiag Vector[E]
+	// External is the external matrix, which is based on the M4 matrix if t is a multiple of 4.
+	External Matrix[E]
+	// M4 is the 4x4 block of the external matrix.
+	M4 Poseidon2M4
+	// Internal is the internal matrix.
+	Internal       Matrix[E]
+	Alpha          *big.Int
+	FullRounds     int
+	HalfFullRounds int
+	PartialRounds  int
+
+	// perm is the network of the permutation, which is built once with the constants.
+	perm *SPN[E]
+}
+
+// Poseidon2M4 is the 4x4 block of the external matrix for t >= 4.
+type Poseidon2M4 int
+
+const (
+	// HorizenM4 is [[5, 7, 1, 3], [4, 6, 1, 1], [1, 3, 5, 7], [1, 1, 4, 6]] of the paper,
+	// which is used by the reference implementation.
+	HorizenM4 Poseidon2M4 = iota
+	// Plonky3M4 is [[2, 3, 1, 1], [1, 2, 3, 1], [1, 1, 2, 3], [3, 1, 1, 2]] of plonky3.
+	Plonky3M4
+)
+
+// matrix returns the entries of the M4 matrix.
+func (m Poseidon2M4) matrix() [4][4]uint64 {
+	if m == Plonky3M4 {
+		return [4][4]uint64{{2, 3, 1, 1}, {1, 2, 3, 1}, {1, 1, 2, 3}, {3, 1, 1, 2}}
+	}
+
+	return [4][4]uint64{{5, 7, 1, 3}, {4, 6, 1, 1}, {1, 3, 5, 7}, {1, 1, 4, 6}}
+}
+
+// poseidon2InternalDiags are the published diagonals of the internal matrices (minus one) for t >= 4,
+// which are generated by https://github.com/HorizenLabs/poseidon2/blob/main/poseidon2_rust_params.sage.
+// the key is the modulus in hex.
+var poseidon2InternalDiags = map[string]map[int][]string{
+	// BN254
+	"30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001": {
+		4: {
+			"10dc6e9c006ea38b04b1e03b4bd9490c0d03f98929ca1d7fb56821fd19d3b6e7",
+			"0c28145b6a44df3e0149b3d0a30b3bb599df9756d4dd9b84a86b38cfb45a740b",
+			"00544b8338791518b2c7645a50392798b21f75bb60e3596170067d00141cac15",
+			"222c01175718386f2e2e82eb122789e352e105a3b8fa852613bc534433ee428b",
+		},
+		8: {
+			"05bffb5e301d8c468c35e24eb2165b6b71725fb7ac9a48efe5ce041bdb05676d",
+			"2aa7a81812688343fc6d78073312996d75f4c5505db0ed22af5ec0df7888cdc7",
+			"2f5856fd71dab60d78cc3af15a89c1e4d61ba189849a4cea10acc1dd228faf00",
+			"12299a260999ac95d271e184968cda40bd4358877a6dcf43d779251fffa61348",
+			"1443aad4693d692a62a8e21f03d5643a123f0c8783a3d27c275f9d01089685fb",
+			"21561b0204a44488082e31472f5885a3adc179bb278233aedc4b316369ec9937",
+			"0c7cc2afa53f9898f30a69b294a4e24f6b2176e1ae0ca49b021792d55e34e97d",
+			"2dd221096053de389fae88e7caa5c43ab55e22aeb758ee130d1246c1dff47b53",
+		},
+		12: {
+			"20bb1e98f40bfe80b8e2f2c885ea13a2ea1f146ff61218c31075ad79dd8f4ffa",
+			"1ed6abd05c8d678fa14c0c77d90f02fa1f8af249915fa6518f5f8d5e5c649fb2",
+			"29107b18658b47d566f5063975d6bbc504382b81777e0796c7cb81f9b4e2cf46",
+			"134ceec3ec069dd76fc9804ff029c2c27c5646986ff5dbeb17091d9c479ae923",
+			"0ee2e4f4a3c23a1b71834d1a95ea402504b8d68fab6f74855c884df898c286f9",
+			"0a469d3f3cb250181cc1e70c8227dace349b1c52fe8f7375744c8be5b80771e5",
+			"0cfa92ab38d116f1cdc24ecc083ad1cb17a215de9968726a81970dde9cca70d6",
+			"173df1a0df85f4533605f9578b1f58ccadf1f810e1fcca66808382efa84d684a",
+			"2ced3bf3cf641c12a311b16b4107663388876a894e70c28c5498946c7fd8dcd4",
+			"0912073a16428c84bfbb6170108adf6d168100ac5587c23c9405cf1f7ca8f13b",
+			"250b310cd13063ee49c78680c1434853968f464d2fc99166c23a2e330dd71d54",
+			"08b593b39852f7ad095a03a3eee546c388e02896d460454c6f1887c8d1c82e37",
+		},
+		16: {
+			"269aaf7c0e0ae1a709c1b7cd137c366a3ef21c0ca7d9fb2b33b5a1ae235768e4",
+			"30543ee04032614e317229edfaf3b27da10dd0792f35ecb2fb82a20c30eb1de3",
+			"017416b13160b7d8d73ffd44efc75ce642f1d002e332ad4bd68469b8b83c5fc4",
+			"09b103f438a43f1aabb6bc5d3490d3c443d773b966d902d36c81490614939eaf",
+			"08f9e81ea21aa882da55bde42c830d261462c4489451ab181513614983fcdb30",
+			"026d2cf77cf485777fb797f7c3bf17acafcb3679549ac98acb6e430eb53e4be5",
+			"0652442bfa09590b710b3273f0d3c3de61defe08359aa8289b63f36eec1d7a7b",
+			"0d6e46bf1e3725ff884f82602321db7d05c152349b4cd1117195e5f778f9c27b",
+			"285754e689291a5f02e4a3c9b07359d3fc33a687a755f842cc45a037774d0542",
+			"09a4884b8ce2a5dc8eee7e181526dd65567e70aa4cb62c3d128e7d94345a4dc4",
+			"06af44dac4ca6cc95e692a20907607defa711623ca94934bea9d70bd555a594d",
+			"0f8b7738afe6bd0d66cb58970bf7484be2c67a4519d1406f074ae165ab5d2ad5",
+			"294dbe90e673accdcc6d7211bb0ac3aab902a88476ef7f7ac6fe3ba7b128c71a",
+			"05c3f9cecad533b14bace3f9d7d7713ccf40c9429b4fce2cfa3aa4ee3d4ae039",
+			"26cbff872ac3df2a3787878f24ee28b6ad4f1dcab41126b80f4038f40510d7e1",
+			"1ba0b493c987b9c1424ede9239ba100dc005e717aa71ca6d6a605561e379bdce",
+		},
+	},
+}
+
+// GenPoseidon2Constants generates the poseidon2 constants of the given width,
+// the width should be 2, 3 or a multiple of 4 (up to 24).
+// for t >= 4, the diagonal of the internal matrix (minus one) should be provided
+// unless it is published for the field, see poseidon2InternalDiags.
+// the round constants are generated by the grain lfsr, and
+// the round numbers are computed by the script of the reference implementation.
+func GenPoseidon2Constants[E Element[E]](width int, internalDiag []E) (*Poseidon2Const[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	alpha := sboxAlpha[E]()
+
+	// round numbers.
+	rf, rp := calcPoseidon2RoundNumbers[E](width, alpha)
+	if rf%2 != 0 {
+		return nil, fmt.Errorf("full rounds should be even")
+	}
+
+	// the s-box x^alpha is denoted by 0 in the grain lfsr.
+	grain := NewGrainLFSR[E](1, 0, Bits[E](), width, rf, rp)
+	constants := make([]E, (rf+rp)*width)
+	for i := 0; i < len(constants); i++ {
+		// only the first constant of each partial round is generated, the others are set to zero.
+		if r := i / width; r >= rf/2 && r < rf/2+rp && i%width != 0 {
+			constants[i] = zero[E]()
+			continue
+		}
+
+		constants[i] = grain.NextFieldElement()
+	}
+
+	if internalDiag == nil {
+		var err error
+		internalDiag, err = defaultInternalDiag[E](width)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewPoseidon2Constants(width, rf, rp, alpha, constants, internalDiag)
+}
+
+// NewPoseidon2Constants creates the poseidon2 constants from the given (published) constants,
+// the constants of the partial rounds should be padded with zeros to the width.
+func NewPoseidon2Constants[E Element[E]](width, rf, rp, alpha int, constants, internalDiag []E) (*Poseidon2Const[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	return newPoseidon2Constants(width, rf, rp, alpha, constants, internalDiag, HorizenM4)
+}
+
+// newPoseidon2Constants creates the poseidon2 constants with the given M4 matrix.
+func newPoseidon2Constants[E Element[E]](width, rf, rp, alpha int, constants, internalDiag []E, m4 Poseidon2M4) (*Poseidon2Const[E], error) {
+	if width != 2 && width != 3 && (width%4 != 0 || width > 24) {
+		return nil, fmt.Errorf("width %d should be 2, 3 or a multiple of 4 (up to 24)", width)
+	}
+
+	if rf%2 != 0 {
+		return nil, fmt.Errorf("full rounds should be even")
+	}
+
+	if len(constants) != (rf+rp)*width {
+		return nil, fmt.Errorf("round constants length %d is inconsistent, want %d", len(constants), (rf+rp)*width)
+	}
+
+	if len(internalDiag) != width {
+		return nil, fmt.Errorf("internal diagonal length %d is inconsistent with the width %d", len(internalDiag), width)
+	}
+
+	external := genExternalMatrix[E](width, m4)
+	internal := genInternalMatrix(internalDiag)
+	if !IsInvertible(internal) {
+		return nil, errors.New("the internal matrix is not invertible")
+	}
+
+	c := &Poseidon2Const[E]{
+		RoundConsts:    constants,
+		InternalDiag:   internalDiag,
+		External:       external,
+		M4:             m4,
+		Internal:       internal,
+		Alpha:          big.NewInt(int64(alpha)),
+		FullRounds:     rf,
+		HalfFullRounds: rf / 2,
+		PartialRounds:  rp,
+	}
+	c.perm = c.newSPN()
+
+	return c, nil
+}
+
+// defaultInternalDiag returns the diagonal of the internal matrix (minus one).
+// for t = 2 and 3, the internal matrices are [[2, 1], [1, 3]] and [[2, 1, 1], [1, 2, 1], [1, 1, 3]].
+func defaultInternalDiag[E Element[E]](width int) ([]E, error) {
+	switch width {
+	case 2:
+		return []E{one[E](), NewElement[E]().SetUint64(2)}, nil
+	case 3:
+		return []E{one[E](), one[E](), NewElement[E]().SetUint64(2)}, nil
+	}
+
+	diags, ok := poseidon2InternalDiags[Modulus[E]().Text(16)]
+	if !ok || diags[width] == nil {
+		return nil, fmt.Errorf("the internal diagonal of width %d should be provided", width)
+	}
+
+	return hexToElementErr[E](diags[width])
+}
+
+// genExternalMatrix generates the external matrix,
+// for t = 2 and 3, the matrix is circ(2, 1) and circ(2, 1, 1),
+// for t = 4, the matrix is M4, and for t = 4k (k > 1), the matrix is circ(2·M4, M4, ..., M4).
+func genExternalMatrix[E Element[E]](width int, m4 Poseidon2M4) Matrix[E] {
+	m := make([][]E, width)
+
+	if width < 4 {
+		for i := 0; i < width; i++ {
+			m[i] = make([]E, width)
+			for j := 0; j < width; j++ {
+				if i == j {
+					m[i][j] = NewElement[E]().SetUint64(2)
+				} else {
+					m[i][j] = one[E]()
+				}
+			}
+		}
+
+		return m
+	}
+
+	entries := m4.matrix()
+	for i := 0; i < width; i++ {
+		m[i] = make([]E, width)
+		for j := 0; j < width; j++ {
+			e := entries[i%4][j%4]
+			if width > 4 && i/4 == j/4 {
+				e *= 2
+			}
+			m[i][j] = NewElement[E]().SetUint64(e)
+		}
+	}
+
+	return m
+}
+
+// genInternalMatrix generates the internal matrix diag(diag) + 1·1ᵀ.
+func genInternalMatrix[E Element[E]](diag []E) Matrix[E] {
+	m := make([][]E, len(diag))
+	for i := 0; i < len(diag); i++ {
+		m[i] = make([]E, len(diag))
+		for j := 0; j < len(diag); j++ {
+			if i == j {
+				m[i][j] = NewElement[E]().Add(diag[i], one[E]())
+			} else {
+				m[i][j] = one[E]()
+			}
+		}
+	}
+
+	return m
+}
+
+// Poseidon2Permute applies the poseidon2 permutation to the whole state.
+func Poseidon2Permute[E Element[E]](input []*big.Int, pdsConsts *Poseidon2Const[E]) ([]*big.Int, error) {
+	if len(input) != len(pdsConsts.InternalDiag) {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), len(pdsConsts.InternalDiag))
+	}
+
+	state := bigToElement[E](input)
+	pdsConsts.permute(state)
+
+	return elementToBig(state), nil
+}
+
+// permute computes the poseidon2 permutation in place.
+func (c *Poseidon2Const[E]) permute(state []E) {
+	c.spn().Permute(state, nil)
+}
+
+// spn returns the network of the poseidon2 permutation, it is built if the constants are not created
+// by this package (e.g. a struct literal).
+func (c *Poseidon2Const[E]) spn() *SPN[E] {
+	if c.perm == nil {
+		return c.newSPN()
+	}
+
+	return c.perm
+}
+
+// newSPN creates the network of the poseidon2 permutation, the full rounds use the external matrix,
+// and the partial rounds only add the round constant to the first element and use the internal matrix.
+func (c *Poseidon2Const[E]) newSPN() *SPN[E] {
+	t := len(c.InternalDiag)
+	rounds := c.FullRounds + c.PartialRounds
+	schedule := HadesSchedule{FullRounds: c.FullRounds, PartialRounds: c.PartialRounds}
+
+	pre := make([][]E, rounds)
+	for r := 0; r < rounds; r++ {
+		if schedule.Kind(r) == PartialRound {
+			pre[r] = c.RoundConsts[r*t : r*t+1]
+		} else {
+			pre[r] = c.RoundConsts[r*t : (r+1)*t]
+		}
+	}
+
+	linear := poseidon2Linear[E]{c}
+
+	return &SPN[E]{
+		Schedule:  schedule,
+		SBox:      PowerSBox[E]{Alpha: c.Alpha},
+		Linear:    linear,
+		Initial:   linear,
+		PreConsts: pre,
+	}
+}
+
+// poseidon2Linear is the linear layer of poseidon2, the initial layer and the full rounds use the external matrix,
+// and the partial rounds use the internal matrix.
+type poseidon2Linear[E Element[E]] struct {
+	c *Poseidon2Const[E]
+}
+
+func (l poseidon2Linear[E]) Apply(dst, state []E, r int, tmp E) {
+	c := l.c
+	if r >= c.HalfFullRounds && r < c.HalfFullRounds+c.PartialRounds {
+		productInternalMatrix(dst, state, c.InternalDiag, tmp)
+	} else {
+		productExternalMatrix(dst, state, c.M4, tmp)
+	}
+}
+
+// productExternalMatrix computes the product between the external matrix and the elements, and writes it into dst,
+// which does not overlap the state, tmp is a scratch element.
+func productExternalMatrix[E Element[E]](dst, state []E, m4 Poseidon2M4, tmp E) {
+	t := len(state)
+	if t < 4 {
+		// circ(2, 1) or circ(2, 1, 1), x_i = x_i + sum.
+		tmp.SetZero()
+		for i := 0; i < t; i++ {
+			tmp.Add(tmp, state[i])
+		}
+
+		for i := 0; i < t; i++ {
+			dst[i].Add(state[i], tmp)
+		}
+
+		return
+	}
+
+	// apply M4 to each chunk.
+	for i := 0; i < t; i += 4 {
+		if m4 == Plonky3M4 {
+			productPlonky3M4(dst[i:i+4], state[i:i+4], tmp)
+		} else {
+			productM4(dst[i:i+4], state[i:i+4], tmp)
+		}
+	}
+
+	if t == 4 {
+		return
+	}
+
+	// add the sums of the chunks, the sum of the j-th elements is only added to the j-th elements.
+	for j := 0; j < 4; j++ {
+		tmp.SetZero()
+		for i := j; i < t; i += 4 {
+			tmp.Add(tmp, dst[i])
+		}
+
+		for i := j; i < t; i += 4 {
+			dst[i].Add(dst[i], tmp)
+		}
+	}
+}
+
+// productM4 computes y = M4*x, see https://eprint.iacr.org/2023/323.pdf page 16,
+// the intermediate values t0, ..., t5 are kept in y and tmp.
+func productM4[E Element[E]](y, x []E, tmp E) {
+	// y1 = t0 = x0 + x1, y3 = t1 = x2 + x3.
+	y[1].Add(x[0], x[1])
+	y[3].Add(x[2], x[3])
+	// y0 = t2 = 2x1 + t1, y2 = t3 = 2x3 + t0.
+	y[0].Add(x[1], x[1])
+	y[0].Add(y[0], y[3])
+	y[2].Add(x[3], x[3])
+	y[2].Add(y[2], y[1])
+	// y3 = t4 = 4t1 + t3, y1 = t5 = 4t0 + t2.
+	y[3].Add(y[3], y[3])
+	y[3].Add(y[3], y[3])
+	y[3].Add(y[3], y[2])
+	y[1].Add(y[1], y[1])
+	y[1].Add(y[1], y[1])
+	y[1].Add(y[1], y[0])
+	// y0 = t3 + t5, y2 = t2 + t4.
+	tmp.Add(y[0], y[3])
+	y[0].Add(y[2], y[1])
+	y[2].Set(tmp)
+}
+
+// productPlonky3M4 computes y = M4*x with the M4 matrix of plonky3, tmp is a scratch element.
+func productPlonky3M4[E Element[E]](y, x []E, tmp E) {
+	// y0 = t01, y2 = t23, tmp = t0123, y1 = t01123, y3 = t01233.
+	y[0].Add(x[0], x[1])
+	y[2].Add(x[2], x[3])
+	tmp.Add(y[0], y[2])
+	y[1].Add(tmp, x[1])
+	y[3].Add(tmp, x[3])
+	// y0 = 2x0 + 3x1 + x2 + x3, y2 = x0 + x1 + 2x2 + 3x3.
+	y[0].Add(y[0], y[1])
+	y[2].Add(y[2], y[3])
+	// y3 = 3x0 + x1 + x2 + 2x3, y1 = x0 + 2x1 + 3x2 + x3.
+	y[3].Add(y[3], x[0])
+	y[3].Add(y[3], x[0])
+	y[1].Add(y[1], x[2])
+	y[1].Add(y[1], x[2])
+}
+
+// productInternalMatrix computes the product between the internal matrix and the elements, and writes it into dst,
+// which does not overlap the state, y_i = x_i * diag_i + sum, tmp is a scratch element.
+func productInternalMatrix[E Element[E]](dst, state []E, diag []E, tmp E) {
+	tmp.SetZero()
+	for i := 0; i < len(state); i++ {
+		tmp.Add(tmp, state[i])
+	}
+
+	for i := 0; i < len(state); i++ {
+		dst[i].Mul(state[i], diag[i])
+		dst[i].Add(dst[i], tmp)
+	}
+}
+
+// sboxAlpha returns the smallest integer alpha >= 3 such that gcd(alpha, p-1) = 1,
+// then x^alpha is a permutation of the field.
+func sboxAlpha[E Element[E]]() int {
+	pMinusOne := new(big.Int).Sub(Modulus[E](), big.NewInt(1))
+	for alpha := int64(3); ; alpha += 2 {
+		if new(big.Int).GCD(nil, nil, big.NewInt(alpha), pMinusOne).Cmp(big.NewInt(1)) == 0 {
+			return int(alpha)
+		}
+	}
+}
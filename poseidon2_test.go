@@ -0,0 +1,181 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoseidon2RoundNumbers(t *testing.T) {
+	// the round numbers of the reference implementation.
+	for _, c := range []struct{ t, rf, rp int }{{2, 8, 56}, {3, 8, 56}, {4, 8, 56}, {8, 8, 57}, {12, 8, 57}, {16, 8, 57}} {
+		rf, rp := calcPoseidon2RoundNumbers[*bn254fr.Element](c.t, 5)
+		assert.Equal(t, c.rf, rf)
+		assert.Equal(t, c.rp, rp)
+	}
+
+	for _, c := range []struct{ t, rf, rp int }{{2, 8, 56}, {3, 8, 56}, {4, 8, 56}, {8, 8, 57}} {
+		rf, rp := calcPoseidon2RoundNumbers[*fr.Element](c.t, 5)
+		assert.Equal(t, c.rf, rf)
+		assert.Equal(t, c.rp, rp)
+	}
+}
+
+func TestPoseidon2Constants(t *testing.T) {
+	// the first and the last round constants published by the reference implementation.
+	cases := []struct {
+		width       int
+		rp          int
+		first, last string
+	}{
+		{4, 56, "19b849f69450b06848da1d39bd5e4a4302bb86744edc26238b0878e269ed23e5", "176563472456aaa746b694c60e1823611ef39039b2edc7ff391e6f2293d2c404"},
+		{8, 57, "0dad22d08a6b8d81d4a5ffc34b9677a7c5254c85e953551f9eba9a0a97590c10", "28bfc4dc9594e9add687744b79f4feb979e2143d742d4e4d1d5bb64bb799a02a"},
+		{12, 57, "0f43621f15c4af9c4e52180b3ab6fbc9cf57863dc34e6190232841eb8b085634", "11ea79fb35b1a82c8bb6a9e1a17cc058975d8953279f362fc4c64944ea6c0b3d"},
+		{16, 57, "11a8c50ae2baf9f5e8b3c672c7326f002cdec557448fdfa263f0adfbaaceacff", "115107e62902f6facbd8a1caa2a2f67a15301e5c034cb99c764f6402ca331781"},
+	}
+
+	for _, c := range cases {
+		cons, err := GenPoseidon2Constants[*bn254fr.Element](c.width, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 8, cons.FullRounds)
+		assert.Equal(t, c.rp, cons.PartialRounds)
+		assert.Equal(t, (8+c.rp)*c.width, len(cons.RoundConsts))
+		assert.Equal(t, hexToElement[*bn254fr.Element]([]string{c.first})[0], cons.RoundConsts[0])
+		assert.Equal(t, hexToElement[*bn254fr.Element]([]string{c.last})[0], cons.RoundConsts[len(cons.RoundConsts)-1])
+
+		// the unused constants of the partial rounds are zero.
+		assert.Equal(t, zero[*bn254fr.Element](), cons.RoundConsts[4*c.width+1])
+	}
+
+	// the internal diagonal is not published.
+	_, err := GenPoseidon2Constants[*fr.Element](4, nil)
+	assert.Error(t, err)
+
+	// invalid width.
+	_, err = GenPoseidon2Constants[*bn254fr.Element](5, nil)
+	assert.Error(t, err)
+}
+
+func TestPoseidon2Permute(t *testing.T) {
+	cases := []struct {
+		input  []*big.Int
+		expect []string
+	}{
+		// the test vector of the reference implementation, see
+		// https://github.com/HorizenLabs/poseidon2/blob/main/plain_implementations/src/poseidon2/poseidon2_instance_bn256.rs.
+		{
+			[]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)},
+			[]string{
+				"0bb61d24daca55eebcb1929a82650f328134334da98ea4f847f760054f4a3033",
+				"303b6f7c86d043bfcbcc80214f26a30277a15d3f74ca654992defe7ff8d03570",
+				"1ed25194542b12eef8617361c3ba7c52e660b145994427cc86296242cf766ec8",
+			},
+		},
+		// barretenberg (poseidon2.test.cpp) uses the t = 4 instance of the reference implementation.
+		{
+			[]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3)},
+			[]string{
+				"01bd538c2ee014ed5141b29e9ae240bf8db3fe5b9a38629a9647cf8d76c01737",
+				"239b62e7db98aa3a2a8f6a0d2fa1709e7a35959aa6c7034814d9daa90cbac662",
+				"04cbb44c61d928ed06808456bf758cbf0c18d1e15a7b6dbc8245fa7515d5e3cb",
+				"2e11c5cff2a22c64d01304b778d78f6998eff1ab73163a35603f54794c30847a",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		cons, err := GenPoseidon2Constants[*bn254fr.Element](len(c.input), nil)
+		assert.NoError(t, err)
+
+		output, err := Poseidon2Permute(c.input, cons)
+		assert.NoError(t, err)
+		assert.Equal(t, hexToBig(c.expect), output)
+
+		_, err = Poseidon2Permute(c.input[1:], cons)
+		assert.Error(t, err)
+	}
+}
+
+func TestPoseidon2PermuteBLS12381(t *testing.T) {
+	// the test vectors of the reference implementation, see
+	// https://github.com/HorizenLabs/poseidon2/blob/main/plain_implementations/src/poseidon2/poseidon2_instance_bls12.rs.
+	cases := []struct {
+		input  []*big.Int
+		expect []string
+	}{
+		{
+			[]*big.Int{big.NewInt(0), big.NewInt(1)},
+			[]string{
+				"73c46dd530e248a87b61d19e67fa1b4ed30fc3d09f16531fe189fb945a15ce4e",
+				"1f0e305ee21c9366d5793b80251405032a3fee32b9dd0b5f4578262891b043b4",
+			},
+		},
+		{
+			[]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)},
+			[]string{
+				"1b152349b1950b6a8ca75ee4407b6e26ca5cca5650534e56ef3fd45761fbf5f0",
+				"4c5793c87d51bdc2c08a32108437dc0000bd0275868f09ebc5f36919af5b3891",
+				"1fc8ed171e67902ca49863159fe5ba6325318843d13976143b8125f08b50dc6b",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		cons, err := GenPoseidon2Constants[*fr.Element](len(c.input), nil)
+		assert.NoError(t, err)
+
+		output, err := Poseidon2Permute(c.input, cons)
+		assert.NoError(t, err)
+		assert.Equal(t, hexToBig(c.expect), output)
+	}
+}
+
+func TestPoseidon2Matrices(t *testing.T) {
+	// the fast products are consistent with the external and internal matrices.
+	for _, width := range []int{2, 3, 4, 8, 12, 16} {
+		cons, err := GenPoseidon2Constants[*bn254fr.Element](width, nil)
+		assert.NoError(t, err)
+
+		newState := func() []*bn254fr.Element {
+			state := make([]*bn254fr.Element, width)
+			for i := 0; i < width; i++ {
+				state[i] = new(bn254fr.Element).SetUint64(uint64(i*i + 7))
+			}
+			return state
+		}
+		state := newState()
+
+		expect, err := LeftMatMul(cons.External, state)
+		assert.NoError(t, err)
+		// dst does not overlap the state.
+		get := newElements[*bn254fr.Element](width)
+		productExternalMatrix(get, state, cons.M4, new(bn254fr.Element))
+		assert.True(t, IsVecEqual(expect, get))
+
+		// the M4 matrix of plonky3.
+		expect, err = LeftMatMul(genExternalMatrix[*bn254fr.Element](width, Plonky3M4), state)
+		assert.NoError(t, err)
+		get = newElements[*bn254fr.Element](width)
+		productExternalMatrix(get, state, Plonky3M4, new(bn254fr.Element))
+		assert.True(t, IsVecEqual(expect, get))
+
+		expect, err = LeftMatMul(cons.Internal, state)
+		assert.NoError(t, err)
+		get = newElements[*bn254fr.Element](width)
+		productInternalMatrix(get, state, cons.InternalDiag, new(bn254fr.Element))
+		assert.True(t, IsVecEqual(expect, get))
+	}
+}
+
+func BenchmarkPoseidon2Permute(b *testing.B) {
+	cons, _ := GenPoseidon2Constants[*bn254fr.Element](3, nil)
+	input := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Poseidon2Permute(input, cons)
+	}
+}
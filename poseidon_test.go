@@ -2,11 +2,14 @@ package poseidon
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"os"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -125,6 +128,95 @@ func TestPoseidonHashFixed(t *testing.T) {
 	assert.Equal(t, expected, hash)
 }
 
+func TestPoseidonGoldilocks(t *testing.T) {
+	cons, err := GenPoseidonConstants[*goldilocks.Element](12)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(7), cons.Alpha)
+	assert.Equal(t, 8, cons.FullRounds)
+	assert.Equal(t, 22, cons.PartialRounds)
+	assert.NoError(t, cons.Verify())
+
+	// the state of 3 elements is too small for 128-bit security.
+	_, err = GenPoseidonConstants[*goldilocks.Element](3)
+	assert.Error(t, err)
+}
+
+func TestPoseidonFullRoundsOnly(t *testing.T) {
+	mds := genMDS[*fr.Element](3)
+	for _, rf := range []int{8, 55} {
+		cons, err := GenCustomPoseidonConstants[*fr.Element](3, 1, 1, rf, 0, mds)
+		assert.NoError(t, err)
+		assert.Equal(t, rf/2, cons.HalfFullRounds)
+		assert.Empty(t, cons.Sparse)
+		// all hash modes agree.
+		assert.NoError(t, cons.Verify())
+	}
+
+	// the full rounds should be even around the partial rounds.
+	_, err := GenCustomPoseidonConstants[*fr.Element](3, 1, 1, 7, 57, mds)
+	assert.Error(t, err)
+	_, err = GenCustomPoseidonConstants[*fr.Element](3, 1, 1, 0, 0, mds)
+	assert.Error(t, err)
+}
+
+func TestPoseidonHalfFullRounds(t *testing.T) {
+	// the pre-sparse matrix is applied in the last of the first half full rounds,
+	// which should not assume rf = 8.
+	mds := genMDS[*fr.Element](3)
+	input := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	for _, rf := range []int{2, 4, 6, 10} {
+		cons, err := GenCustomPoseidonConstants[*fr.Element](3, 1, 1, rf, 10, mds)
+		assert.NoError(t, err)
+
+		h1, err := Hash(input, cons, OptimizedStatic)
+		assert.NoError(t, err)
+		h2, err := Hash(input, cons, Correct)
+		assert.NoError(t, err)
+		assert.Equal(t, h2, h1)
+	}
+}
+
+func TestPoseidonConstVerify(t *testing.T) {
+	for _, width := range []int{2, 3, 5} {
+		cons, err := GenPoseidonConstants[*fr.Element](width)
+		assert.NoError(t, err)
+		assert.NoError(t, cons.Verify())
+	}
+
+	tests := []struct {
+		modify func(c *PoseidonConst[*fr.Element])
+		want   string
+	}{
+		{func(c *PoseidonConst[*fr.Element]) { c.RoundConsts = c.RoundConsts[1:] }, "round constants length"},
+		{func(c *PoseidonConst[*fr.Element]) { c.CompRoundConsts[5] = one[*fr.Element]() }, "compressed round constants"},
+		{func(c *PoseidonConst[*fr.Element]) { c.Sparse[3].V[0] = one[*fr.Element]() }, "sparse matrix"},
+		{func(c *PoseidonConst[*fr.Element]) { c.PreSparse[1][1] = one[*fr.Element]() }, "sparse matrix 0"},
+		{func(c *PoseidonConst[*fr.Element]) { c.Mds.mInv[0][0] = one[*fr.Element]() }, "mds inverse matrix"},
+		{func(c *PoseidonConst[*fr.Element]) { c.HalfFullRounds = 3 }, "half full rounds"},
+		{func(c *PoseidonConst[*fr.Element]) { c.Sparse = c.Sparse[1:] }, "sparse matrices length"},
+	}
+
+	for _, cases := range tests {
+		cons, err := GenPoseidonConstants[*fr.Element](3)
+		assert.NoError(t, err)
+		cases.modify(cons)
+		err = cons.Verify()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), cases.want)
+	}
+
+	// the constants are replaced by another consistent set, but the hash modes still use the networks built at the creation.
+	cons, err := GenPoseidonConstants[*fr.Element](3)
+	assert.NoError(t, err)
+	other, err := GenReferenceConstants[*fr.Element](3, 8, 57)
+	assert.NoError(t, err)
+	cons.Mds, cons.RoundConsts, cons.CompRoundConsts = other.Mds, other.RoundConsts, other.CompRoundConsts
+	cons.PreSparse, cons.Sparse = other.PreSparse, other.Sparse
+	cons.FullRounds, cons.HalfFullRounds, cons.PartialRounds = other.FullRounds, other.HalfFullRounds, other.PartialRounds
+	assert.ErrorContains(t, cons.Verify(), "modified after the creation")
+	assert.NoError(t, other.Verify())
+}
+
 func benchmarkStatic(b *testing.B, str []string) {
 	cons, _ := GenPoseidonConstants[*fr.Element](len(str) + 1)
 	input := hexToBig(str)
@@ -145,6 +237,26 @@ func BenchmarkOptimizedStaticWith8Inputs(b *testing.B)  { benchmarkStatic(b, str
 func BenchmarkOptimizedStaticWith9Inputs(b *testing.B)  { benchmarkStatic(b, strs[8]) }
 func BenchmarkOptimizedStaticWith10Inputs(b *testing.B) { benchmarkStatic(b, strs[9]) }
 
+func benchmarkHash[E Element[E]](b *testing.B, mode HashMode) {
+	for width := 3; width <= 12; width++ {
+		cons, _ := GenPoseidonConstants[E](width)
+		input := make([]*big.Int, width-1)
+		for i := 0; i < len(input); i++ {
+			input[i] = big.NewInt(int64(i))
+		}
+
+		b.Run(fmt.Sprintf("width=%d", width), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = Hash(input, cons, mode)
+			}
+		})
+	}
+}
+
+func BenchmarkHashBLS12381(b *testing.B) { benchmarkHash[*fr.Element](b, OptimizedStatic) }
+func BenchmarkHashBN254(b *testing.B)    { benchmarkHash[*bn254fr.Element](b, OptimizedStatic) }
+
 func benchmarkDynamic(b *testing.B, str []string) {
 	cons, _ := GenPoseidonConstants[*fr.Element](len(str) + 1)
 	input := hexToBig(str)
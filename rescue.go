@@ -0,0 +1,315 @@
+package poseidon
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// RescuePrimeConst is the constants used in the rescue-prime permutation,
+// see https://eprint.iacr.org/2020/1143.pdf.
+// each round consists of the forward s-box layer x^alpha and the inverse s-box layer x^(1/alpha),
+// both of which are followed by the mds matrix and the round constants.
+type RescuePrimeConst[E Element[E]] struct {
+	Mds Matrix[E]
+	// RoundConsts are 2*m*N round constants.
+	RoundConsts   []E
+	Alpha         *big.Int
+	AlphaInv      *big.Int
+	Width         int
+	Capacity      int
+	SecurityLevel int
+	Rounds        int
+	// Optimized is true for rescue-prime optimized, where each half round applies
+	// the mds matrix and the round constants before the s-box layer, see GenRPOConstants.
+	Optimized bool
+}
+
+// GenRescuePrimeConstants generates the rescue-prime constants as in the reference implementation
+// of the specification, the alpha is the smallest integer such that gcd(alpha, p-1) = 1,
+// the mds matrix is derived from a vandermonde matrix of the smallest primitive element,
+// and the round constants are sampled from SHAKE256.
+func GenRescuePrimeConstants[E Element[E]](width, capacity, securityLevel int) (*RescuePrimeConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	if capacity <= 0 || capacity >= width {
+		return nil, fmt.Errorf("capacity %d should be in (0, %d)", capacity, width)
+	}
+
+	alpha := sboxAlpha[E]()
+	rounds := calcRescuePrimeRounds(width, capacity, securityLevel, alpha)
+
+	mds, err := genRescuePrimeMDS[E](width)
+	if err != nil {
+		return nil, fmt.Errorf("generate mds matrix err: %w", err)
+	}
+
+	seed := fmt.Sprintf("Rescue-XLIX(%d,%d,%d,%d)", Modulus[E](), width, capacity, securityLevel)
+	constants := genRescuePrimeRoundConsts[E](seed, width, rounds)
+
+	return NewRescuePrimeConstants(width, capacity, securityLevel, rounds, alpha, mds, constants)
+}
+
+// NewRescuePrimeConstants creates the rescue-prime constants from the given (published) constants.
+func NewRescuePrimeConstants[E Element[E]](width, capacity, securityLevel, rounds, alpha int, mds Matrix[E], constants []E) (*RescuePrimeConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	if capacity <= 0 || capacity >= width {
+		return nil, fmt.Errorf("capacity %d should be in (0, %d)", capacity, width)
+	}
+
+	if len(mds) != width || !IsSquareMatrix(mds) {
+		return nil, fmt.Errorf("mds matrix should be a %d x %d matrix", width, width)
+	}
+
+	if !IsInvertible(mds) {
+		return nil, errors.New("the mds matrix is not invertible")
+	}
+
+	if len(constants) != 2*width*rounds {
+		return nil, fmt.Errorf("round constants length %d is inconsistent, want %d", len(constants), 2*width*rounds)
+	}
+
+	// the inverse s-box x^(1/alpha) requires alpha*alphaInv = 1 mod p-1.
+	pMinusOne := new(big.Int).Sub(Modulus[E](), big.NewInt(1))
+	alphaInv := new(big.Int).ModInverse(big.NewInt(int64(alpha)), pMinusOne)
+	if alphaInv == nil {
+		return nil, fmt.Errorf("x^%d is not a permutation of the field", alpha)
+	}
+
+	return &RescuePrimeConst[E]{
+		Mds:           mds,
+		RoundConsts:   constants,
+		Alpha:         big.NewInt(int64(alpha)),
+		AlphaInv:      alphaInv,
+		Width:         width,
+		Capacity:      capacity,
+		SecurityLevel: securityLevel,
+		Rounds:        rounds,
+	}, nil
+}
+
+// calcRescuePrimeRounds computes the number of rounds against the groebner basis attack,
+// then a minimum value (5) and a 50% security margin are applied.
+func calcRescuePrimeRounds(m, capacity, securityLevel, alpha int) int {
+	rate := m - capacity
+	target := new(big.Int).Lsh(big.NewInt(1), uint(securityLevel))
+
+	l1 := 1
+	for ; l1 < 25; l1++ {
+		dcon := int64(math.Floor(0.5*float64(alpha-1)*float64(m)*float64(l1-1) + 2))
+		v := int64(m*(l1-1) + rate)
+
+		b := new(big.Int).Binomial(v+dcon, v)
+		if b.Mul(b, b).Cmp(target) > 0 {
+			break
+		}
+	}
+
+	if l1 < 5 {
+		l1 = 5
+	}
+
+	return (3*l1 + 1) / 2
+}
+
+// genRescuePrimeMDS generates the mds matrix, which is the transpose of the right half
+// of the echelon form of the m x 2m vandermonde matrix V_ij = g^(i*j).
+func genRescuePrimeMDS[E Element[E]](m int) (Matrix[E], error) {
+	g := Info[E]().Generator()
+
+	// the left and right halves of the vandermonde matrix.
+	left := make([][]E, m)
+	right := make([][]E, m)
+	for i := 0; i < m; i++ {
+		left[i] = make([]E, m)
+		right[i] = make([]E, m)
+
+		// g^i
+		gi := NewElement[E]()
+		Exp(gi, g, big.NewInt(int64(i)))
+
+		e := one[E]()
+		for j := 0; j < 2*m; j++ {
+			if j < m {
+				left[i][j] = NewElement[E]().Set(e)
+			} else {
+				right[i][j-m] = NewElement[E]().Set(e)
+			}
+			e.Mul(e, gi)
+		}
+	}
+
+	// the echelon form is [I | left^-1 * right].
+	leftInv, err := Invert[E](left)
+	if err != nil {
+		return nil, fmt.Errorf("invert vandermonde matrix err: %w", err)
+	}
+
+	ech, err := MatMul[E](leftInv, right)
+	if err != nil {
+		return nil, fmt.Errorf("compute echelon form err: %w", err)
+	}
+
+	return transpose[E](ech), nil
+}
+
+// genRescuePrimeRoundConsts samples 2*m*N round constants from SHAKE256 of the seed,
+// each constant is read from ceil(n/8)+1 bytes in little-endian order, and reduced by p.
+func genRescuePrimeRoundConsts[E Element[E]](seed string, m, rounds int) []E {
+	bytesPerInt := Bytes[E]() + 1
+
+	shake := sha3.NewShake256()
+	_, _ = shake.Write([]byte(seed))
+
+	constants := make([]E, 2*m*rounds)
+	chunk := make([]byte, bytesPerInt)
+	for i := 0; i < len(constants); i++ {
+		_, _ = shake.Read(chunk)
+
+		// little-endian to big-endian.
+		be := make([]byte, bytesPerInt)
+		for j := 0; j < bytesPerInt; j++ {
+			be[bytesPerInt-1-j] = chunk[j]
+		}
+
+		constants[i] = NewElement[E]().SetBigInt(new(big.Int).SetBytes(be))
+	}
+
+	return constants
+}
+
+// RescuePrimePermute applies the rescue-prime permutation to the whole state.
+func RescuePrimePermute[E Element[E]](input []*big.Int, pdsConsts *RescuePrimeConst[E]) ([]*big.Int, error) {
+	if len(input) != pdsConsts.Width {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(input), pdsConsts.Width)
+	}
+
+	state := bigToElement[E](input)
+	state = pdsConsts.permute(state)
+
+	return elementToBig(state), nil
+}
+
+// permute computes the rescue-prime permutation, each round of the specification is
+// two rounds of the network, which alternate the forward and the inverse s-box layers.
+func (c *RescuePrimeConst[E]) permute(state []E) []E {
+	return c.spn().Permute(state, nil)
+}
+
+// spn returns the network of the rescue-prime permutation,
+// the round constants are added after the mds matrix.
+func (c *RescuePrimeConst[E]) spn() *SPN[E] {
+	m := c.Width
+	if c.Optimized {
+		return c.optimizedSPN()
+	}
+
+	sbox := func(state []E, r int, _ RoundKind, tmp E) {
+		if r%2 == 0 {
+			sboxLayer(state, c.Alpha, tmp)
+		} else {
+			sboxLayer(state, c.AlphaInv, tmp)
+		}
+	}
+
+	linear := func(dst, state []E, r int, tmp E) {
+		productMatrix(dst, state, c.Mds, tmp)
+		addConsts(dst, c.RoundConsts[r*m:(r+1)*m])
+	}
+
+	return &SPN[E]{
+		Schedule: FullSchedule(2 * c.Rounds),
+		SBox:     SBoxLayerFunc[E](sbox),
+		Linear:   LinearLayerFunc[E](linear),
+	}
+}
+
+// optimizedSPN returns the network of rescue-prime optimized, where the initial linear layer is the first
+// mds matrix and round constants, each round of the network is an s-box layer followed by the next mds matrix
+// and round constants, and the last s-box layer is not followed by the linear layer.
+func (c *RescuePrimeConst[E]) optimizedSPN() *SPN[E] {
+	m := c.Width
+	last := 2*c.Rounds - 1
+
+	sbox := func(state []E, r int, _ RoundKind, tmp E) {
+		if r%2 == 0 {
+			sboxLayer(state, c.Alpha, tmp)
+		} else {
+			sboxLayer(state, c.AlphaInv, tmp)
+		}
+	}
+
+	linear := func(dst, state []E, r int, tmp E) {
+		if r == last {
+			for i := 0; i < m; i++ {
+				dst[i].Set(state[i])
+			}
+			return
+		}
+
+		productMatrix(dst, state, c.Mds, tmp)
+		addConsts(dst, c.RoundConsts[(r+1)*m:(r+2)*m])
+	}
+
+	return &SPN[E]{
+		Schedule: FullSchedule(2 * c.Rounds),
+		SBox:     SBoxLayerFunc[E](sbox),
+		Linear:   LinearLayerFunc[E](linear),
+		Initial:  LinearLayerFunc[E](linear),
+	}
+}
+
+// RescuePrimeHash computes the rescue-prime sponge hash, the input is padded with a single one
+// and zeros to a multiple of the rate, and the first rate elements of the state are returned.
+func RescuePrimeHash[E Element[E]](input []*big.Int, pdsConsts *RescuePrimeConst[E]) ([]*big.Int, error) {
+	rate := pdsConsts.Width - pdsConsts.Capacity
+
+	padded := bigToElement[E](input)
+	padded = append(padded, one[E]())
+	for len(padded)%rate != 0 {
+		padded = append(padded, zero[E]())
+	}
+
+	state := make([]E, pdsConsts.Width)
+	for i := 0; i < len(state); i++ {
+		state[i] = zero[E]()
+	}
+
+	// absorb.
+	for i := 0; i < len(padded); i += rate {
+		for j := 0; j < rate; j++ {
+			state[j].Add(state[j], padded[i+j])
+		}
+		state = pdsConsts.permute(state)
+	}
+
+	// squeeze.
+	return elementToBig(state[:rate]), nil
+}
+
+// sboxLayer computes x^alpha for all elements in place, tmp is a scratch element.
+func sboxLayer[E Element[E]](state []E, alpha *big.Int, tmp E) {
+	for i := 0; i < len(state); i++ {
+		tmp.Set(state[i])
+		Exp(state[i], tmp, alpha)
+	}
+}
+
+// productMatrix computes the product between the matrix and the elements, M*x, and writes it into dst.
+func productMatrix[E Element[E]](dst, state []E, mds Matrix[E], tmp E) {
+	for i := 0; i < len(state); i++ {
+		dst[i].SetZero()
+		for j := 0; j < len(state); j++ {
+			tmp.Mul(mds[i][j], state[j])
+			dst[i].Add(dst[i], tmp)
+		}
+	}
+}
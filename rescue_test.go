@@ -0,0 +1,162 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRescuePrimeRounds(t *testing.T) {
+	// the minimum value 5 and the 50% security margin.
+	assert.Equal(t, 8, calcRescuePrimeRounds(12, 4, 128, 7))
+	assert.Equal(t, 14, calcRescuePrimeRounds(3, 1, 128, 5))
+	assert.Equal(t, 8, calcRescuePrimeRounds(3, 1, 64, 5))
+}
+
+func TestRescuePrimeConstants(t *testing.T) {
+	cons, err := GenRescuePrimeConstants[*fr.Element](3, 1, 128)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), cons.Alpha.Int64())
+	assert.Equal(t, 14, cons.Rounds)
+	assert.Equal(t, 2*3*14, len(cons.RoundConsts))
+
+	// the expected values are computed by a python port of the reference sage script.
+	assert.Equal(t, hexToElement[*fr.Element]([]string{"157"})[0], cons.Mds[0][0])
+	assert.Equal(t, hexToElement[*fr.Element]([]string{"4e79ebb1e5a43abef900bd773cdde906e4bf3244749cb64424f7db47ba0dda87"})[0], cons.RoundConsts[0])
+	assert.Equal(t, hexToElement[*fr.Element]([]string{"022335910ed632e5c9e2d372efd6e6841fb5fee114f08d90dc081a5a59445404"})[0], cons.RoundConsts[len(cons.RoundConsts)-1])
+
+	// alpha * alphaInv = 1 mod p-1.
+	x := new(fr.Element).SetUint64(12345)
+	y, z := new(fr.Element), new(fr.Element)
+	Exp(y, x, cons.Alpha)
+	Exp(z, y, cons.AlphaInv)
+	assert.Equal(t, x, z)
+
+	_, err = GenRescuePrimeConstants[*fr.Element](3, 3, 128)
+	assert.Error(t, err)
+}
+
+func TestRescuePrime(t *testing.T) {
+	cons, err := GenRescuePrimeConstants[*fr.Element](3, 1, 128)
+	assert.NoError(t, err)
+
+	output, err := RescuePrimePermute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, hexToBig([]string{
+		"2e1183b4ae571061ed9514118392ede2904ae1376d61653de09083cf0b31abce",
+		"38f9e521c67c329a53403dd42999b19c3bfe355e594752c87ada74da35c74b85",
+		"69a193e3c2734c26d85d191a1e521c1bc8024c9047bb5c79835ed5cfc2d8440e",
+	}), output)
+
+	h, err := RescuePrimeHash([]*big.Int{big.NewInt(1), big.NewInt(2)}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, hexToBig([]string{
+		"5d87015dfb62279a3dd4b271658e028e7d2a971fa2588b12b600d8d2f439aebb",
+		"0c4fd77e3245d00d08c0330314630b4ca7dfbd65c6256093b95276fa5334061b",
+	}), h)
+
+	_, err = RescuePrimePermute([]*big.Int{big.NewInt(0)}, cons)
+	assert.Error(t, err)
+}
+
+func TestRPOConstants(t *testing.T) {
+	cons, err := GenRPOConstants()
+	assert.NoError(t, err)
+	assert.True(t, cons.Optimized)
+	assert.Equal(t, int64(7), cons.Alpha.Int64())
+	assert.Equal(t, 2*12*7, len(cons.RoundConsts))
+
+	// the first round constants (ARK1[0] and ARK2[0]) and the mds matrix of miden-crypto's Rpo256.
+	for i, c := range []uint64{5789762306288267392, 6522564764413701783, 17809893479458208203} {
+		assert.Equal(t, c, cons.RoundConsts[i].Uint64())
+	}
+	for i, c := range []uint64{6077062762357204287, 15277620170502011191} {
+		assert.Equal(t, c, cons.RoundConsts[12+i].Uint64())
+	}
+	assert.Equal(t, uint64(23), cons.Mds[0][1].Uint64())
+	assert.Equal(t, uint64(8), cons.Mds[1][0].Uint64())
+	assert.Equal(t, uint64(7), cons.Mds[1][1].Uint64())
+}
+
+func TestRPOPermute(t *testing.T) {
+	cons, err := GenRPOConstants()
+	assert.NoError(t, err)
+
+	var input [12]uint64
+	for i := range input {
+		input[i] = uint64(i)
+	}
+	out, err := RPOPermute(input)
+	assert.NoError(t, err)
+
+	// each round computes M, ARK1, x^7, M, ARK2, x^(1/7).
+	state := make([]*goldilocks.Element, 12)
+	for i := range state {
+		state[i] = new(goldilocks.Element).SetUint64(input[i])
+	}
+	tmp := new(goldilocks.Element)
+	for r := 0; r < 7; r++ {
+		for half, alpha := range []*big.Int{cons.Alpha, cons.AlphaInv} {
+			next := newElements[*goldilocks.Element](12)
+			productMatrix(next, state, cons.Mds, tmp)
+			addConsts(next, cons.RoundConsts[(2*r+half)*12:(2*r+half+1)*12])
+			sboxLayer(next, alpha, tmp)
+			state = next
+		}
+	}
+	for i := range state {
+		assert.Equal(t, state[i].Uint64(), out[i])
+	}
+}
+
+func TestRPOHash(t *testing.T) {
+	permute := func(state [12]uint64) [12]uint64 {
+		out, err := RPOPermute(state)
+		assert.NoError(t, err)
+		return out
+	}
+
+	// a full chunk is not padded.
+	var state [12]uint64
+	for i := 0; i < 8; i++ {
+		state[4+i] = uint64(i + 1)
+	}
+	out := permute(state)
+	h, err := RPOHash([]uint64{1, 2, 3, 4, 5, 6, 7, 8})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{out[4], out[5], out[6], out[7]}, h)
+
+	// a short chunk is padded with 1 and zeros, and the capacity is initialized to the length mod 8.
+	state = [12]uint64{1, 0, 0, 0, 9, 1}
+	out = permute(state)
+	h, err = RPOHash([]uint64{9})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{out[4], out[5], out[6], out[7]}, h)
+
+	state = [12]uint64{3, 0, 0, 0, 9, 8, 7, 1}
+	out = permute(state)
+	h, err = RPOHash([]uint64{9, 8, 7})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{out[4], out[5], out[6], out[7]}, h)
+
+	// 8 + 3 elements, the capacity is initialized to 3 before the first chunk, and the second chunk is padded.
+	state = [12]uint64{3, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8}
+	out = permute(state)
+	state = out
+	state[4], state[5], state[6], state[7] = 9, 8, 7, 1
+	state[8], state[9], state[10], state[11] = 0, 0, 0, 0
+	out = permute(state)
+	h, err = RPOHash([]uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 8, 7})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{out[4], out[5], out[6], out[7]}, h)
+
+	// merge.
+	state = [12]uint64{0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8}
+	out = permute(state)
+	h, err = RPOMerge([4]uint64{1, 2, 3, 4}, [4]uint64{5, 6, 7, 8})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint64{out[4], out[5], out[6], out[7]}, h)
+}
@@ -0,0 +1,144 @@
+package poseidon
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// the parameters of rescue-prime optimized over goldilocks, the state is 4 capacity elements
+// followed by 8 rate elements, and the digest is the first 4 rate elements.
+const (
+	rpoWidth         = 12
+	rpoCapacity      = 4
+	rpoRate          = 8
+	rpoRounds        = 7
+	rpoSecurityLevel = 128
+	rpoDigest        = 4
+)
+
+// rpoMdsRow is the first row of the circulant mds matrix of rescue-prime optimized.
+var rpoMdsRow = [rpoWidth]uint64{7, 23, 8, 26, 13, 10, 9, 7, 6, 22, 21, 8}
+
+var (
+	rpoOnce  sync.Once
+	rpoCons  *RescuePrimeConst[*goldilocks.Element]
+	rpoPerm  *SPN[*goldilocks.Element]
+	rpoError error
+)
+
+// GenRPOConstants returns the constants of rescue-prime optimized (https://eprint.iacr.org/2022/1577.pdf)
+// over goldilocks (width 12, capacity 4, x^7, 7 rounds), the mds matrix is circ(rpoMdsRow) for M*x,
+// and the round constants are sampled from SHAKE256 as rescue-prime with the seed "RPO(p,m,c,λ)".
+func GenRPOConstants() (*RescuePrimeConst[*goldilocks.Element], error) {
+	rpoOnce.Do(func() {
+		mds := make(Matrix[*goldilocks.Element], rpoWidth)
+		for i := 0; i < rpoWidth; i++ {
+			mds[i] = make([]*goldilocks.Element, rpoWidth)
+			for j := 0; j < rpoWidth; j++ {
+				mds[i][j] = new(goldilocks.Element).SetUint64(rpoMdsRow[(j-i+rpoWidth)%rpoWidth])
+			}
+		}
+
+		seed := fmt.Sprintf("RPO(%d,%d,%d,%d)", goldilocksModulus, rpoWidth, rpoCapacity, rpoSecurityLevel)
+		constants := genRescuePrimeRoundConsts[*goldilocks.Element](seed, rpoWidth, rpoRounds)
+
+		rpoCons, rpoError = NewRescuePrimeConstants(rpoWidth, rpoCapacity, rpoSecurityLevel, rpoRounds,
+			sboxAlpha[*goldilocks.Element](), mds, constants)
+		if rpoError != nil {
+			return
+		}
+
+		rpoCons.Optimized = true
+		rpoPerm = rpoCons.spn()
+	})
+
+	return rpoCons, rpoError
+}
+
+// rpoPermute computes the permutation of the state in place.
+func rpoPermute(state []*goldilocks.Element) error {
+	if _, err := GenRPOConstants(); err != nil {
+		return err
+	}
+
+	rpoPerm.Permute(state, nil)
+	return nil
+}
+
+// RPOPermute applies the permutation of rescue-prime optimized to the 12 elements, the values are reduced modulo p.
+func RPOPermute(input [rpoWidth]uint64) ([rpoWidth]uint64, error) {
+	state := make([]*goldilocks.Element, rpoWidth)
+	for i := 0; i < rpoWidth; i++ {
+		state[i] = new(goldilocks.Element).SetUint64(input[i])
+	}
+
+	var out [rpoWidth]uint64
+	if err := rpoPermute(state); err != nil {
+		return out, err
+	}
+
+	for i := 0; i < rpoWidth; i++ {
+		out[i] = state[i].Uint64()
+	}
+
+	return out, nil
+}
+
+// RPOHash hashes the elements with the sponge of the specification, the inputs overwrite the rate elements
+// 8 at a time, a shorter last chunk is padded with a single one and zeros, the first capacity element
+// is initialized to len(inputs) mod 8 as in miden's hash_elements, and the digest is the first 4 rate elements.
+func RPOHash(inputs []uint64) ([rpoDigest]uint64, error) {
+	state := newElements[*goldilocks.Element](rpoWidth)
+	state[0].SetUint64(uint64(len(inputs) % rpoRate))
+
+	padded := append([]uint64{}, inputs...)
+	if len(padded)%rpoRate != 0 {
+		padded = append(padded, 1)
+		for len(padded)%rpoRate != 0 {
+			padded = append(padded, 0)
+		}
+	}
+
+	for i := 0; i < len(padded); i += rpoRate {
+		for j := 0; j < rpoRate; j++ {
+			state[rpoCapacity+j].SetUint64(padded[i+j])
+		}
+
+		if err := rpoPermute(state); err != nil {
+			return [rpoDigest]uint64{}, err
+		}
+	}
+
+	return rpoDigestOf(state), nil
+}
+
+// RPOMerge computes the 2-to-1 compression of two digests, the digests fill the rate elements
+// with zero capacity, and the digest of the permuted state is returned.
+func RPOMerge(left, right [rpoDigest]uint64) ([rpoDigest]uint64, error) {
+	var input [rpoWidth]uint64
+	copy(input[rpoCapacity:], left[:])
+	copy(input[rpoCapacity+rpoDigest:], right[:])
+
+	state := make([]*goldilocks.Element, rpoWidth)
+	for i := 0; i < rpoWidth; i++ {
+		state[i] = new(goldilocks.Element).SetUint64(input[i])
+	}
+
+	if err := rpoPermute(state); err != nil {
+		return [rpoDigest]uint64{}, err
+	}
+
+	return rpoDigestOf(state), nil
+}
+
+// rpoDigestOf returns the first 4 rate elements of the state.
+func rpoDigestOf(state []*goldilocks.Element) [rpoDigest]uint64 {
+	var out [rpoDigest]uint64
+	for i := 0; i < rpoDigest; i++ {
+		out[i] = state[rpoCapacity+i].Uint64()
+	}
+
+	return out
+}
@@ -0,0 +1,214 @@
+package poseidon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	sageParamsRegexp = regexp.MustCompile(`(\w+)=(\d+)`)
+	sageHexRegexp    = regexp.MustCompile(`'((?:0x)+[0-9a-fA-F]+)'`)
+	sageRowRegexp    = regexp.MustCompile(`\[([^\[\]]*)\]`)
+)
+
+// sageOutput is the output of the reference script.
+type sageOutput struct {
+	params    map[string]int
+	modulus   *big.Int
+	constants []string
+	mds       [][]string
+}
+
+// ParseSageConstants parses the output of the reference script generate_parameters_grain.sage,
+// see https://extgit.iaik.tugraz.at/krypto/hadeshash. The output looks like:
+//
+//	Params: n=255, t=3, alpha=5, M=128, R_F=8, R_P=57
+//	Modulus = 52435875175126190479447740508185965837690552500527637822603658699938581184513
+//	Number of round constants: 195
+//	Round constants for GF(p):
+//	['0x...', '0x...', ...]
+//	...
+//	Prime number: 0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001
+//	MDS matrix:
+//	 [['0x...', '0x...', '0x...'], ['0x...', '0x...', '0x...'], ['0x...', '0x...', '0x...']]
+//
+// The field, the width and the round numbers are checked against E and the constants,
+// the mds matrix is transposed as this library computes state*M,
+// then the sparse and compressed forms are derived in the same way as GenCustomPoseidonConstants.
+func ParseSageConstants[E Element[E]](r io.Reader) (*PoseidonConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	out, err := readSageOutput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// field.
+	if out.modulus == nil {
+		return nil, errors.New("the modulus is missing")
+	}
+
+	if out.modulus.Cmp(Modulus[E]()) != 0 {
+		return nil, fmt.Errorf("the modulus %x is inconsistent with the field %x", out.modulus, Modulus[E]())
+	}
+
+	for _, key := range []string{"n", "t", "alpha", "R_F", "R_P"} {
+		if _, ok := out.params[key]; !ok {
+			return nil, fmt.Errorf("the parameter %s is missing", key)
+		}
+	}
+
+	if out.params["n"] != Bits[E]() {
+		return nil, fmt.Errorf("the field size %d is inconsistent with the field size %d", out.params["n"], Bits[E]())
+	}
+
+	if alpha := sboxAlpha[E](); out.params["alpha"] != alpha {
+		return nil, fmt.Errorf("alpha %d is not supported, want %d", out.params["alpha"], alpha)
+	}
+
+	// width.
+	t := out.params["t"]
+	if len(out.mds) != t {
+		return nil, fmt.Errorf("mds matrix rows %d are inconsistent with the width %d", len(out.mds), t)
+	}
+
+	// round numbers.
+	rf, rp := out.params["R_F"], out.params["R_P"]
+	if rf%2 != 0 {
+		return nil, fmt.Errorf("full rounds should be even")
+	}
+
+	if len(out.constants) != (rf+rp)*t {
+		return nil, fmt.Errorf("round constants length %d is inconsistent with the round numbers, want %d",
+			len(out.constants), (rf+rp)*t)
+	}
+
+	constants, err := hexToElementErr[E](out.constants)
+	if err != nil {
+		return nil, fmt.Errorf("parse round constants err: %w", err)
+	}
+
+	mds := make([][]E, t)
+	for i := 0; i < t; i++ {
+		if len(out.mds[i]) != t {
+			return nil, fmt.Errorf("mds matrix columns %d are inconsistent with the width %d", len(out.mds[i]), t)
+		}
+
+		mds[i], err = hexToElementErr[E](out.mds[i])
+		if err != nil {
+			return nil, fmt.Errorf("parse mds matrix err: %w", err)
+		}
+	}
+
+	// the reference script computes M*x, so the transpose is used as state*M, see genReferenceMDS.
+	return newPoseidonConstants(t, rf, rp, constants, transpose[E](mds))
+}
+
+// readSageOutput reads the parameters, the round constants and the mds matrix.
+func readSageOutput(r io.Reader) (*sageOutput, error) {
+	out := &sageOutput{params: make(map[string]int)}
+
+	// the round constants and the mds matrix are printed in the next line.
+	var next *[]string
+	var nextMds bool
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+		case next != nil:
+			*next = sageHexStrings(line)
+			next = nil
+		case nextMds:
+			for _, match := range sageRowRegexp.FindAllStringSubmatch(line, -1) {
+				out.mds = append(out.mds, sageHexStrings(match[1]))
+			}
+			nextMds = false
+		case strings.HasPrefix(line, "Params:"):
+			for _, match := range sageParamsRegexp.FindAllStringSubmatch(line, -1) {
+				v, err := strconv.Atoi(match[2])
+				if err != nil {
+					return nil, fmt.Errorf("parse parameter %s err: %w", match[1], err)
+				}
+				out.params[match[1]] = v
+			}
+		case strings.HasPrefix(line, "Modulus"):
+			s := strings.TrimSpace(strings.TrimPrefix(line, "Modulus"))
+			s = strings.TrimSpace(strings.TrimPrefix(s, "="))
+			m, ok := new(big.Int).SetString(s, 10)
+			if !ok {
+				return nil, fmt.Errorf("cannot parse the modulus %q", s)
+			}
+			out.modulus = m
+		case strings.HasPrefix(line, "Prime number:"):
+			s := strings.TrimSpace(strings.TrimPrefix(line, "Prime number:"))
+			// the old version of the script prints the prime with a double 0x prefix.
+			for strings.HasPrefix(s, "0x") {
+				s = strings.TrimPrefix(s, "0x")
+			}
+			m, ok := new(big.Int).SetString(s, 16)
+			if !ok {
+				return nil, fmt.Errorf("cannot parse the prime number %q", s)
+			}
+
+			if out.modulus != nil && out.modulus.Cmp(m) != 0 {
+				return nil, errors.New("the prime number is inconsistent with the modulus")
+			}
+			out.modulus = m
+		case strings.HasPrefix(line, "Round constants for GF(p)"):
+			next = &out.constants
+		case strings.HasPrefix(line, "Round constants for GF(2^n)"):
+			return nil, errors.New("only prime fields are supported")
+		case strings.HasPrefix(line, "MDS matrix:"):
+			// the matrix may be printed in the same line.
+			rest := strings.TrimSpace(strings.TrimPrefix(line, "MDS matrix:"))
+			if rest == "" {
+				nextMds = true
+			} else {
+				for _, match := range sageRowRegexp.FindAllStringSubmatch(rest, -1) {
+					out.mds = append(out.mds, sageHexStrings(match[1]))
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read sage output err: %w", err)
+	}
+
+	if len(out.constants) == 0 {
+		return nil, errors.New("the round constants are missing")
+	}
+
+	if len(out.mds) == 0 {
+		return nil, errors.New("the mds matrix is missing")
+	}
+
+	return out, nil
+}
+
+// sageHexStrings extracts the quoted hex-strings in a python list.
+func sageHexStrings(line string) []string {
+	var res []string
+	for _, match := range sageHexRegexp.FindAllStringSubmatch(line, -1) {
+		s := match[1]
+		for strings.HasPrefix(s, "0x") {
+			s = strings.TrimPrefix(s, "0x")
+		}
+		res = append(res, s)
+	}
+
+	return res
+}
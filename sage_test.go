@@ -0,0 +1,93 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// sageOutputOf prints the constants in the same format as generate_parameters_grain.sage,
+// the mds matrix is printed as M for M*x.
+func sageOutputOf(cons *PoseidonConst[*fr.Element], n int) string {
+	t := row(cons.Mds.m)
+	quote := func(e []*fr.Element) string {
+		hex := elementToHex(e)
+		for i := range hex {
+			hex[i] = "'0x" + hex[i] + "'"
+		}
+		return "[" + strings.Join(hex, ", ") + "]"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Params: n=%d, t=%d, alpha=5, M=128, R_F=%d, R_P=%d\n", n, t, cons.FullRounds, cons.PartialRounds)
+	fmt.Fprintf(&b, "Modulus = %s\n", Modulus[*fr.Element]().String())
+	fmt.Fprintf(&b, "Number of round constants: %d\n", len(cons.RoundConsts))
+	fmt.Fprintf(&b, "Round constants for GF(p):\n%s\n", quote(cons.RoundConsts))
+	fmt.Fprintf(&b, "n: %d\nt: %d\nN: %d\n", n, t, n*t)
+	fmt.Fprintf(&b, "Result Algorithm 1:\n [True, 0]\nResult Algorithm 2:\n [True, None]\nResult Algorithm 3:\n [True, None]\n")
+	fmt.Fprintf(&b, "Prime number: 0x0x%s\n", Modulus[*fr.Element]().Text(16))
+	mds := transpose[*fr.Element](cons.Mds.m)
+	rows := make([]string, t)
+	for i := 0; i < t; i++ {
+		rows[i] = quote(mds[i])
+	}
+	fmt.Fprintf(&b, "MDS matrix:\n [%s]\n", strings.Join(rows, ","))
+
+	return b.String()
+}
+
+func TestParseSageConstants(t *testing.T) {
+	// the output of the reference script for circomlib (bn254, t = 3, rf = 8, rp = 57),
+	// the hash of [1, 2] (domain tag 0, output state[0]) is the one of go-iden3-crypto.
+	f, err := os.Open("./data/sage-bn254-t3.txt")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	circom, err := ParseSageConstants[*bn254fr.Element](f)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, circom.Verify())
+
+	state, err := Permute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}, circom)
+	assert.NoError(t, err)
+	expected, _ := new(big.Int).SetString("7853200120776062878684798364095072458815029376092732009249414926327459813530", 10)
+	assert.Equal(t, expected, state[0])
+
+	ref, err := GenReferenceConstants[*bn254fr.Element](3, 8, 57)
+	assert.NoError(t, err)
+	assert.Equal(t, ref, circom)
+
+	cons, err := GenPoseidonConstants[*fr.Element](3)
+	assert.NoError(t, err)
+
+	get, err := ParseSageConstants[*fr.Element](strings.NewReader(sageOutputOf(cons, 255)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, cons, get)
+	assert.NoError(t, get.Verify())
+
+	// the field is inconsistent.
+	_, err = ParseSageConstants[*bn254fr.Element](strings.NewReader(sageOutputOf(cons, 255)))
+	assert.ErrorContains(t, err, "modulus")
+
+	_, err = ParseSageConstants[*fr.Element](strings.NewReader(sageOutputOf(cons, 254)))
+	assert.ErrorContains(t, err, "field size")
+
+	// the round numbers are inconsistent.
+	out := strings.Replace(sageOutputOf(cons, 255), "R_P=55", "R_P=56", 1)
+	_, err = ParseSageConstants[*fr.Element](strings.NewReader(out))
+	assert.ErrorContains(t, err, "round constants length")
+
+	// the width is inconsistent.
+	out = strings.Replace(sageOutputOf(cons, 255), "t=3", "t=4", 1)
+	_, err = ParseSageConstants[*fr.Element](strings.NewReader(out))
+	assert.ErrorContains(t, err, "width")
+}
@@ -0,0 +1,113 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+)
+
+// the parameters of snarkvm's poseidon over the scalar field of BLS12-377 (the base field of Edwards BLS12),
+// the capacity is 1, and the rate is 2, 4 or 8.
+const (
+	snarkVMCapacity      = 1
+	snarkVMFullRounds    = 8
+	snarkVMPartialRounds = 31
+	snarkVMAlpha         = 17
+	// snarkVMScalarDataBits is the number of bits kept by hash_to_scalar, that is, one bit less than the modulus of the scalar field
+	// of Edwards BLS12 (2111115437357092606062206234695386632838870926408408195193685246394721360383).
+	snarkVMScalarDataBits = 250
+)
+
+// SnarkVMConst is one of snarkvm's poseidon instances (Poseidon2, Poseidon4 and Poseidon8 of the console network).
+type SnarkVMConst struct {
+	Poseidon *PoseidonConst[*bls12377fr.Element]
+	// Domain is the domain separator, which is the little-endian integer of the domain string.
+	Domain *bls12377fr.Element
+	Rate   int
+}
+
+// GenSnarkVMConstants generates the constants of snarkvm's poseidon with the given rate (2, 4 or 8)
+// and the domain separator "AleoPoseidon<rate>".
+func GenSnarkVMConstants(rate int) (*SnarkVMConst, error) {
+	return GenSnarkVMConstantsWithDomain(rate, fmt.Sprintf("AleoPoseidon%d", rate))
+}
+
+// GenSnarkVMConstantsWithDomain generates the constants of snarkvm's poseidon with the given rate and domain string,
+// snarkvm samples the round constants and the mds matrix by the grain lfsr (find_poseidon_ark_and_mds),
+// which is the same as the reference script with 8 full rounds, 31 partial rounds and x^17, see GenReferenceConstants.
+// the state is the capacity element followed by the rate elements.
+func GenSnarkVMConstantsWithDomain(rate int, domain string) (*SnarkVMConst, error) {
+	if rate != 2 && rate != 4 && rate != 8 {
+		return nil, fmt.Errorf("rate %d should be 2, 4 or 8", rate)
+	}
+
+	cons, err := GenReferenceConstants[*bls12377fr.Element](rate+snarkVMCapacity, snarkVMFullRounds, snarkVMPartialRounds)
+	if err != nil {
+		return nil, err
+	}
+	cons.Alpha = big.NewInt(snarkVMAlpha)
+
+	// the bytes of the domain are in little-endian.
+	le := []byte(domain)
+	be := make([]byte, len(le))
+	for i := range le {
+		be[len(le)-1-i] = le[i]
+	}
+
+	return &SnarkVMConst{
+		Poseidon: cons,
+		Domain:   new(bls12377fr.Element).SetBigInt(new(big.Int).SetBytes(be)),
+		Rate:     rate,
+	}, nil
+}
+
+// SnarkVMHash computes snarkvm's hash, which is the first element of SnarkVMHashMany.
+func SnarkVMHash(input []*big.Int, cons *SnarkVMConst) (*big.Int, error) {
+	out, err := SnarkVMHashMany(input, 1, cons)
+	if err != nil {
+		return nil, err
+	}
+
+	return out[0], nil
+}
+
+// SnarkVMHashMany computes snarkvm's hash_many, the preimage [domain, len(input), 0, ..., 0, input] (the domain and the length
+// are padded with zeros to the rate) is absorbed into the duplex sponge, and then numOutputs elements are squeezed.
+func SnarkVMHashMany(input []*big.Int, numOutputs int, cons *SnarkVMConst) ([]*big.Int, error) {
+	if numOutputs < 0 || numOutputs > 1<<16-1 {
+		return nil, fmt.Errorf("number of outputs %d should be a 16-bit integer", numOutputs)
+	}
+
+	elems, err := canonicalToElement[*bls12377fr.Element](input)
+	if err != nil {
+		return nil, err
+	}
+
+	preimage := newElements[*bls12377fr.Element](cons.Rate)
+	preimage[0].Set(cons.Domain)
+	preimage[1].SetUint64(uint64(len(input)))
+	preimage = append(preimage, elems...)
+
+	spn := cons.Poseidon.staticSPN()
+	sponge := newDuplexSponge[*bls12377fr.Element](snarkVMCapacity, cons.Rate, func(state []*bls12377fr.Element) {
+		spn.Permute(state, nil)
+	})
+	sponge.absorb(preimage)
+
+	return elementToBig(sponge.squeeze(numOutputs)), nil
+}
+
+// SnarkVMHashToScalar computes snarkvm's hash_to_scalar, the hash is truncated to the lower 250 bits,
+// which is a scalar of Edwards BLS12.
+func SnarkVMHashToScalar(input []*big.Int, cons *SnarkVMConst) (*big.Int, error) {
+	h, err := SnarkVMHash(input, cons)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := new(big.Int).Lsh(big.NewInt(1), snarkVMScalarDataBits)
+	mask.Sub(mask, big.NewInt(1))
+
+	return h.And(h, mask), nil
+}
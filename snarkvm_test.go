@@ -0,0 +1,82 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	bls12377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnarkVMConstants(t *testing.T) {
+	// the shapes of the constants are checked, the entries are not compared with the ones of snarkvm.
+	for _, rate := range []int{2, 4, 8} {
+		cons, err := GenSnarkVMConstants(rate)
+		assert.NoError(t, err)
+		assert.Equal(t, rate, cons.Rate)
+		assert.Equal(t, rate+1, row(cons.Poseidon.Mds.m))
+		assert.Equal(t, 8, cons.Poseidon.FullRounds)
+		assert.Equal(t, 31, cons.Poseidon.PartialRounds)
+		assert.Equal(t, big.NewInt(17), cons.Poseidon.Alpha)
+		assert.NoError(t, cons.Poseidon.Verify())
+	}
+
+	// "AleoPoseidon2" in little-endian.
+	cons, err := GenSnarkVMConstants(2)
+	assert.NoError(t, err)
+	assert.Equal(t, felt("326e6f646965736f506f656c41"), cons.Domain.BigInt(new(big.Int)))
+
+	_, err = GenSnarkVMConstants(3)
+	assert.Error(t, err)
+}
+
+func TestSnarkVMHash(t *testing.T) {
+	cons, err := GenSnarkVMConstants(2)
+	assert.NoError(t, err)
+
+	zero := big.NewInt(0)
+	domain := cons.Domain.BigInt(new(big.Int))
+	a, b, c := big.NewInt(1), big.NewInt(2), big.NewInt(3)
+	add := func(x, y *big.Int) *big.Int {
+		z := new(big.Int).Add(x, y)
+		return z.Mod(z, Modulus[*bls12377fr.Element]())
+	}
+
+	// the preimage (domain, 3, a, b, c) fills the rate twice and then c is absorbed,
+	// the state is (capacity, rate...).
+	state, err := Permute([]*big.Int{zero, domain, big.NewInt(3)}, cons.Poseidon)
+	assert.NoError(t, err)
+	state[1], state[2] = add(state[1], a), add(state[2], b)
+	state, err = Permute(state, cons.Poseidon)
+	assert.NoError(t, err)
+	state[1] = add(state[1], c)
+	state, err = Permute(state, cons.Poseidon)
+	assert.NoError(t, err)
+
+	h, err := SnarkVMHash([]*big.Int{a, b, c}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, state[1], h)
+
+	// 3 outputs permute again after the rate.
+	out, err := SnarkVMHashMany([]*big.Int{a, b, c}, 3, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, state[1:], out[:2])
+	state, err = Permute(state, cons.Poseidon)
+	assert.NoError(t, err)
+	assert.Equal(t, state[1], out[2])
+
+	// the lower 250 bits.
+	s, err := SnarkVMHashToScalar([]*big.Int{a, b, c}, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Mod(h, new(big.Int).Lsh(big.NewInt(1), 250)), s)
+
+	// the length is a part of the preimage.
+	h1, err := SnarkVMHash([]*big.Int{a}, cons)
+	assert.NoError(t, err)
+	h2, err := SnarkVMHash([]*big.Int{a, zero}, cons)
+	assert.NoError(t, err)
+	assert.NotEqual(t, h1, h2)
+
+	_, err = SnarkVMHash([]*big.Int{Modulus[*bls12377fr.Element]()}, cons)
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package poseidon
+
+import "math/big"
+
+// RoundKind is the kind of the s-box layer of a round.
+type RoundKind int
+
+const (
+	// FullRound applies the s-box to all elements of the state.
+	FullRound RoundKind = iota
+	// PartialRound applies the s-box to the first element of the state.
+	PartialRound
+)
+
+// RoundSchedule describes the number of rounds and the kind of each round.
+type RoundSchedule interface {
+	NumRounds() int
+	Kind(round int) RoundKind
+}
+
+// SBoxLayer is the non-linear layer of a round, it is applied to the state in place,
+// tmp is a scratch element which can be used without allocation.
+type SBoxLayer[E Element[E]] interface {
+	Apply(state []E, round int, kind RoundKind, tmp E)
+}
+
+// LinearLayer is the linear layer of a round, it writes the new state into dst, which does not overlap the state,
+// tmp is a scratch element which can be used without allocation.
+// the initial linear layer (if any) is applied with round -1.
+type LinearLayer[E Element[E]] interface {
+	Apply(dst, state []E, round int, tmp E)
+}
+
+// SBoxLayerFunc is an adapter to use a function as the s-box layer.
+type SBoxLayerFunc[E Element[E]] func(state []E, round int, kind RoundKind, tmp E)
+
+func (f SBoxLayerFunc[E]) Apply(state []E, round int, kind RoundKind, tmp E) {
+	f(state, round, kind, tmp)
+}
+
+// LinearLayerFunc is an adapter to use a function as the linear layer.
+type LinearLayerFunc[E Element[E]] func(dst, state []E, round int, tmp E)
+
+func (f LinearLayerFunc[E]) Apply(dst, state []E, round int, tmp E) {
+	f(dst, state, round, tmp)
+}
+
+// InPlaceLinearLayerFunc is an adapter to use a function which updates the state in place as the linear layer.
+type InPlaceLinearLayerFunc[E Element[E]] func(state []E, round int)
+
+func (f InPlaceLinearLayerFunc[E]) Apply(dst, state []E, round int, _ E) {
+	for i := 0; i < len(state); i++ {
+		dst[i].Set(state[i])
+	}
+
+	f(dst, round)
+}
+
+// HadesSchedule is the schedule of the HADES design strategy,
+// the partial rounds are in the middle of the full rounds.
+type HadesSchedule struct {
+	FullRounds    int
+	PartialRounds int
+}
+
+func (s HadesSchedule) NumRounds() int {
+	return s.FullRounds + s.PartialRounds
+}
+
+func (s HadesSchedule) Kind(round int) RoundKind {
+	if round >= s.FullRounds/2 && round < s.FullRounds/2+s.PartialRounds {
+		return PartialRound
+	}
+
+	return FullRound
+}
+
+// FullSchedule is the schedule of the given number of full rounds.
+type FullSchedule int
+
+func (s FullSchedule) NumRounds() int {
+	return int(s)
+}
+
+func (s FullSchedule) Kind(int) RoundKind {
+	return FullRound
+}
+
+// PowerSBox is the s-box layer x^Alpha.
+type PowerSBox[E Element[E]] struct {
+	Alpha *big.Int
+}
+
+func (s PowerSBox[E]) Apply(state []E, _ int, kind RoundKind, tmp E) {
+	if kind == PartialRound {
+		state = state[:1]
+	}
+
+	sboxLayer(state, s.Alpha, tmp)
+}
+
+// MdsLayer multiplies the state (as a row vector) by the matrix, that is, state*M.
+type MdsLayer[E Element[E]] struct {
+	M Matrix[E]
+}
+
+func (l MdsLayer[E]) Apply(dst, state []E, _ int, tmp E) {
+	productMdsMatrix(dst, state, l.M, tmp)
+}
+
+// SPN is a generic substitution-permutation network, each round computes
+//
+//	pre-constants -> s-box layer -> post-constants -> linear layer.
+//
+// the optional initial constants and the initial linear layer are applied before the first round.
+type SPN[E Element[E]] struct {
+	Schedule RoundSchedule
+	SBox     SBoxLayer[E]
+	Linear   LinearLayer[E]
+	// InitialConsts and Initial are skipped if they are nil.
+	InitialConsts []E
+	Initial       LinearLayer[E]
+	// PreConsts and PostConsts are the round constants added before and after the s-box layer,
+	// the constants of a round are added to the first elements of the state, and nil means no constants.
+	PreConsts  [][]E
+	PostConsts [][]E
+}
+
+// Permute computes the permutation in place, and returns the state.
+// if trace is not nil, it is called with the state after each round.
+// the elements of each round are allocated once before the first round, so the rounds do not allocate.
+func (p *SPN[E]) Permute(state []E, trace func(state []E)) []E {
+	input := state
+	swapped := false
+	buf := newElements[E](len(state))
+	tmp := NewElement[E]()
+
+	if p.InitialConsts != nil {
+		addConsts(state, p.InitialConsts)
+	}
+
+	if p.Initial != nil {
+		p.Initial.Apply(buf, state, -1, tmp)
+		state, buf = buf, state
+		swapped = !swapped
+	}
+
+	for r := 0; r < p.Schedule.NumRounds(); r++ {
+		if r < len(p.PreConsts) {
+			addConsts(state, p.PreConsts[r])
+		}
+
+		p.SBox.Apply(state, r, p.Schedule.Kind(r), tmp)
+
+		if r < len(p.PostConsts) {
+			addConsts(state, p.PostConsts[r])
+		}
+
+		p.Linear.Apply(buf, state, r, tmp)
+		state, buf = buf, state
+		swapped = !swapped
+
+		if trace != nil {
+			trace(state)
+		}
+	}
+
+	// the state may end up in the buffer.
+	if swapped {
+		for i := 0; i < len(state); i++ {
+			input[i].Set(state[i])
+		}
+	}
+
+	return input
+}
+
+// addConsts adds the constants to the first len(consts) elements of the state.
+func addConsts[E Element[E]](state []E, consts []E) {
+	for i := 0; i < len(consts); i++ {
+		state[i].Add(state[i], consts[i])
+	}
+}
@@ -0,0 +1,107 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHadesSchedule(t *testing.T) {
+	s := HadesSchedule{FullRounds: 8, PartialRounds: 3}
+	assert.Equal(t, 11, s.NumRounds())
+
+	kinds := []RoundKind{FullRound, FullRound, FullRound, FullRound, PartialRound, PartialRound, PartialRound, FullRound, FullRound, FullRound, FullRound}
+	for r, kind := range kinds {
+		assert.Equal(t, kind, s.Kind(r))
+	}
+
+	assert.Equal(t, 5, FullSchedule(5).NumRounds())
+	assert.Equal(t, FullRound, FullSchedule(5).Kind(3))
+}
+
+func TestSPNModes(t *testing.T) {
+	for width := 2; width < 6; width++ {
+		cons, err := GenPoseidonConstants[*fr.Element](width)
+		assert.NoError(t, err)
+
+		input := make([]*big.Int, width)
+		for i := 0; i < width; i++ {
+			input[i] = big.NewInt(int64(i))
+		}
+
+		dynamic, err := cons.dynamicSPN()
+		assert.NoError(t, err)
+
+		want := cons.correctSPN().Permute(bigToElement[*fr.Element](input), nil)
+		assert.True(t, IsVecEqual(want, cons.staticSPN().Permute(bigToElement[*fr.Element](input), nil)))
+		assert.True(t, IsVecEqual(want, dynamic.Permute(bigToElement[*fr.Element](input), nil)))
+	}
+}
+
+func TestSPNCustom(t *testing.T) {
+	cons, err := GenPoseidonConstants[*fr.Element](3)
+	assert.NoError(t, err)
+
+	// the poseidon permutation assembled from the exported building blocks.
+	rounds := cons.FullRounds + cons.PartialRounds
+	pre := make([][]*fr.Element, rounds)
+	for r := 0; r < rounds; r++ {
+		pre[r] = cons.RoundConsts[3*r : 3*(r+1)]
+	}
+
+	traced := 0
+	spn := &SPN[*fr.Element]{
+		Schedule:  HadesSchedule{FullRounds: cons.FullRounds, PartialRounds: cons.PartialRounds},
+		SBox:      PowerSBox[*fr.Element]{Alpha: PoseidonExp},
+		Linear:    MdsLayer[*fr.Element]{M: cons.Mds.m},
+		PreConsts: pre,
+	}
+
+	input := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}
+	got := spn.Permute(bigToElement[*fr.Element](input), func([]*fr.Element) { traced++ })
+	assert.Equal(t, rounds, traced)
+
+	want, err := Permute(input, cons)
+	assert.NoError(t, err)
+	assert.Equal(t, want, elementToBig(got))
+}
+
+func TestSPNAllocs(t *testing.T) {
+	cons, err := GenPoseidonConstants[*fr.Element](5)
+	assert.NoError(t, err)
+
+	spn := cons.staticSPN()
+	state := bigToElement[*fr.Element]([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4)})
+
+	// the networks are built once with the constants, and reused by each hash.
+	assert.Same(t, spn, cons.staticSPN())
+	dynamic, err := cons.dynamicSPN()
+	assert.NoError(t, err)
+	assert.Same(t, cons.dynamic, dynamic)
+	assert.Same(t, cons.correct, cons.correctSPN())
+
+	// only the buffer of the state and the scratch element are allocated, the rounds do not allocate.
+	allocs := testing.AllocsPerRun(10, func() {
+		spn.Permute(state, nil)
+	})
+	assert.Equal(t, float64(len(state)+2), allocs)
+}
+
+func TestPoseidon2SPNAllocs(t *testing.T) {
+	// t = 3 uses the dense sums, and t >= 4 the M4 products.
+	for _, width := range []int{3, 4, 8, 16} {
+		cons, err := GenPoseidon2Constants[*bn254fr.Element](width, nil)
+		assert.NoError(t, err)
+		assert.Same(t, cons.perm, cons.spn())
+
+		// the external and internal matrices only use the scratch element.
+		state := newElements[*bn254fr.Element](width)
+		allocs := testing.AllocsPerRun(10, func() {
+			cons.permute(state)
+		})
+		assert.Equal(t, float64(len(state)+2), allocs, "width %d", width)
+	}
+}
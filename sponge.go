@@ -0,0 +1,84 @@
+package poseidon
+
+// duplexSponge is the duplex sponge of arkworks (and snarkvm), the state is the capacity elements followed by the rate elements,
+// the input is added to the rate elements, and the state is permuted before the rate elements are overwritten or reused,
+// that is, when more elements are absorbed (squeezed) with a full rate, or when the sponge switches between absorbing and squeezing.
+type duplexSponge[E Element[E]] struct {
+	state    []E
+	capacity int
+	rate     int
+	permute  func(state []E)
+
+	// squeezing is the mode of the sponge, and index is the next rate element to absorb or squeeze.
+	squeezing bool
+	index     int
+}
+
+// newDuplexSponge creates a sponge of the zero state in the absorbing mode.
+func newDuplexSponge[E Element[E]](capacity, rate int, permute func(state []E)) *duplexSponge[E] {
+	return &duplexSponge[E]{
+		state:    newElements[E](capacity + rate),
+		capacity: capacity,
+		rate:     rate,
+		permute:  permute,
+	}
+}
+
+// absorb adds the elements to the rate elements, the state is permuted when the rate is full.
+func (s *duplexSponge[E]) absorb(input []E) {
+	if len(input) == 0 {
+		return
+	}
+
+	if s.squeezing || s.index == s.rate {
+		s.permute(s.state)
+		s.squeezing = false
+		s.index = 0
+	}
+
+	for {
+		// the remaining elements fit in the rate.
+		if s.index+len(input) <= s.rate {
+			for i, x := range input {
+				s.state[s.capacity+s.index+i].Add(s.state[s.capacity+s.index+i], x)
+			}
+			s.index += len(input)
+			return
+		}
+
+		n := s.rate - s.index
+		for i := 0; i < n; i++ {
+			s.state[s.capacity+s.index+i].Add(s.state[s.capacity+s.index+i], input[i])
+		}
+		s.permute(s.state)
+		input = input[n:]
+		s.index = 0
+	}
+}
+
+// squeeze returns n elements from the rate elements, the state is permuted when the rate is exhausted.
+func (s *duplexSponge[E]) squeeze(n int) []E {
+	out := make([]E, 0, n)
+	if !s.squeezing || s.index == s.rate {
+		s.permute(s.state)
+		s.squeezing = true
+		s.index = 0
+	}
+
+	for {
+		// the remaining elements are in the rate.
+		if s.index+n-len(out) <= s.rate {
+			for len(out) < n {
+				out = append(out, NewElement[E]().Set(s.state[s.capacity+s.index]))
+				s.index++
+			}
+			return out
+		}
+
+		for ; s.index < s.rate; s.index++ {
+			out = append(out, NewElement[E]().Set(s.state[s.capacity+s.index]))
+		}
+		s.permute(s.state)
+		s.index = 0
+	}
+}
@@ -0,0 +1,56 @@
+package poseidon
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplexSponge(t *testing.T) {
+	// the permutation adds 1 to each element, so the permutations can be counted.
+	count := 0
+	permute := func(state []*fr.Element) {
+		count++
+		for _, x := range state {
+			x.Add(x, one[*fr.Element]())
+		}
+	}
+
+	elems := func(values ...uint64) []*fr.Element {
+		e := make([]*fr.Element, len(values))
+		for i, v := range values {
+			e[i] = new(fr.Element).SetUint64(v)
+		}
+		return e
+	}
+
+	sponge := newDuplexSponge[*fr.Element](1, 2, permute)
+
+	// a full rate is not permuted until the next absorption.
+	sponge.absorb(elems(1, 2))
+	assert.Equal(t, 0, count)
+	assert.Equal(t, elems(0, 1, 2), sponge.state)
+	sponge.absorb(nil)
+	assert.Equal(t, 0, count)
+
+	// (0, 1, 2) -> (1, 2, 3) + (0, 3, 4), then 5 is added to the first rate element.
+	sponge.absorb(elems(3, 4, 5))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, elems(2, 11, 8), sponge.state)
+
+	// the squeezing permutes the absorbed state, and then the rate elements are read.
+	assert.Equal(t, elems(12, 9, 13), append(sponge.squeeze(1), sponge.squeeze(2)...))
+	assert.Equal(t, 4, count)
+
+	// the absorption after squeezing permutes the state.
+	sponge.absorb(elems(1))
+	assert.Equal(t, 5, count)
+	assert.Equal(t, elems(5, 15, 11), sponge.state)
+
+	// an empty squeeze still switches the mode.
+	assert.Empty(t, sponge.squeeze(0))
+	assert.Equal(t, 6, count)
+	assert.Equal(t, elems(16, 12), sponge.squeeze(2))
+	assert.Equal(t, 6, count)
+}
@@ -0,0 +1,273 @@
+package poseidon
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
+	"path"
+)
+
+// standardConstants are the checked-in constants for BLS12-381 and BN254 at common widths (3, 5, 9, 12, 17, 25, 37).
+// the file name is poseidon-constants-{field}-{sbox}-{bits}-{width}-{rf}-{rp}-{modulus}.txt.
+//
+//go:embed data/poseidon-constants-*.txt
+var standardConstants embed.FS
+
+// constantsFile is the json format of the poseidon constants.
+type constantsFile struct {
+	CompressedRoundConstants []string     `json:"compress"`
+	RoundConstants           []string     `json:"constants"`
+	Mds                      [][]string   `json:"mds"`
+	Sparse                   [][][]string `json:"sparse"`
+	PreSparse                [][]string   `json:"pre_sparse"`
+}
+
+// LoadStandardConstants loads the embedded constants of the given width,
+// if the width is not embedded, the constants are generated by GenPoseidonConstants.
+func LoadStandardConstants[E Element[E]](width int) (*PoseidonConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	name, err := standardConstantsFile[E](width)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return GenPoseidonConstants[E](width)
+	}
+
+	f, err := standardConstants.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open standard constants err: %w", err)
+	}
+	defer f.Close()
+
+	return ReadPoseidonConstants[E](f)
+}
+
+// VerifyStandardConstants checks that the generated constants match the embedded constants of the field.
+func VerifyStandardConstants[E Element[E]]() error {
+	if err := checkModulus[E](); err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(standardConstants, fmt.Sprintf("data/poseidon-constants-1-1-%d-*-%X.txt", Bits[E](), Modulus[E]()))
+	if err != nil {
+		return fmt.Errorf("list standard constants err: %w", err)
+	}
+
+	for _, name := range names {
+		var bits, width, rf, rp int
+		if _, err := fmt.Sscanf(path.Base(name), "poseidon-constants-1-1-%d-%d-%d-%d-", &bits, &width, &rf, &rp); err != nil {
+			return fmt.Errorf("parse the parameters of %s err: %w", name, err)
+		}
+
+		embedded, err := LoadStandardConstants[E](width)
+		if err != nil {
+			return fmt.Errorf("load %s err: %w", name, err)
+		}
+
+		cons, err := GenPoseidonConstants[E](width)
+		if err != nil {
+			return fmt.Errorf("generate constants of width %d err: %w", width, err)
+		}
+
+		if cons.FullRounds != rf || cons.PartialRounds != rp {
+			return fmt.Errorf("%s: round numbers are inconsistent with the generated constants", name)
+		}
+
+		if err := isConstantsEqual(embedded, cons); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// standardConstantsFile returns the name of the embedded constants file,
+// or an empty string if the width is not embedded.
+func standardConstantsFile[E Element[E]](width int) (string, error) {
+	pattern := fmt.Sprintf("data/poseidon-constants-1-1-%d-%d-*-%X.txt", Bits[E](), width, Modulus[E]())
+	names, err := fs.Glob(standardConstants, pattern)
+	if err != nil {
+		return "", fmt.Errorf("find standard constants err: %w", err)
+	}
+
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	if len(names) > 1 {
+		return "", fmt.Errorf("more than one standard constants of width %d", width)
+	}
+
+	return names[0], nil
+}
+
+// ReadPoseidonConstants reads the poseidon constants in json format,
+// which consists of the compressed round constants, the round constants, the mds, sparse and pre-sparse matrices.
+// the dimensions are checked against the width and the round numbers, and the constants are verified by Verify.
+func ReadPoseidonConstants[E Element[E]](r io.Reader) (*PoseidonConst[E], error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	var strs constantsFile
+	if err := json.NewDecoder(r).Decode(&strs); err != nil {
+		return nil, fmt.Errorf("decode constants err: %w", err)
+	}
+
+	width := len(strs.Mds)
+	rp := len(strs.Sparse)
+	if width < 2 || len(strs.RoundConstants)%width != 0 {
+		return nil, errors.New("the round constants are inconsistent with the mds matrix")
+	}
+
+	rf := len(strs.RoundConstants)/width - rp
+	if err := checkRoundNumbers(rf, rp); err != nil {
+		return nil, err
+	}
+
+	// the shapes of the pre-computed constants.
+	for i := 0; i < width; i++ {
+		if len(strs.Mds[i]) != width {
+			return nil, fmt.Errorf("mds matrix should be a %d*%d matrix", width, width)
+		}
+	}
+
+	if len(strs.CompressedRoundConstants) != rf*width+rp {
+		return nil, fmt.Errorf("compressed round constants length %d is inconsistent, want %d",
+			len(strs.CompressedRoundConstants), rf*width+rp)
+	}
+
+	if len(strs.PreSparse) != width {
+		return nil, fmt.Errorf("pre-sparse matrix should be a %d*%d matrix", width, width)
+	}
+
+	for i := 0; i < width; i++ {
+		if len(strs.PreSparse[i]) != width {
+			return nil, fmt.Errorf("pre-sparse matrix should be a %d*%d matrix", width, width)
+		}
+	}
+
+	constants, err := hexToElementErr[E](strs.RoundConstants)
+	if err != nil {
+		return nil, fmt.Errorf("parse round constants err: %w", err)
+	}
+
+	compress, err := hexToElementErr[E](strs.CompressedRoundConstants)
+	if err != nil {
+		return nil, fmt.Errorf("parse compressed round constants err: %w", err)
+	}
+
+	mds, err := hexToMatrix[E](strs.Mds)
+	if err != nil {
+		return nil, fmt.Errorf("parse mds matrix err: %w", err)
+	}
+
+	preSparse, err := hexToMatrix[E](strs.PreSparse)
+	if err != nil {
+		return nil, fmt.Errorf("parse pre-sparse matrix err: %w", err)
+	}
+
+	sparse := make([]*SparseMatrix[E], rp)
+	for i := 0; i < rp; i++ {
+		if len(strs.Sparse[i]) != 2 {
+			return nil, fmt.Errorf("sparse matrix %d should consist of WHat and V", i)
+		}
+
+		if len(strs.Sparse[i][0]) != width || len(strs.Sparse[i][1]) != width-1 {
+			return nil, fmt.Errorf("sparse matrix %d should consist of %d elements in WHat and %d elements in V", i, width, width-1)
+		}
+
+		sparse[i] = new(SparseMatrix[E])
+		if sparse[i].WHat, err = hexToElementErr[E](strs.Sparse[i][0]); err != nil {
+			return nil, fmt.Errorf("parse sparse matrix err: %w", err)
+		}
+
+		if sparse[i].V, err = hexToElementErr[E](strs.Sparse[i][1]); err != nil {
+			return nil, fmt.Errorf("parse sparse matrix err: %w", err)
+		}
+	}
+
+	mdsm, err := deriveMatrices(mds)
+	if err != nil {
+		return nil, fmt.Errorf("create mds matrix err: %w", err)
+	}
+
+	pdsConsts := &PoseidonConst[E]{
+		Mds:             mdsm,
+		RoundConsts:     constants,
+		CompRoundConsts: compress,
+		PreSparse:       preSparse,
+		Sparse:          sparse,
+		FullRounds:      rf,
+		PartialRounds:   rp,
+		HalfFullRounds:  rf / 2,
+		Alpha:           big.NewInt(int64(sboxAlpha[E]())),
+	}
+
+	// the pre-computed constants should be consistent with the round constants and the mds matrix.
+	if err := pdsConsts.Verify(); err != nil {
+		return nil, fmt.Errorf("verify constants err: %w", err)
+	}
+
+	if err := pdsConsts.buildSPNs(); err != nil {
+		return nil, err
+	}
+
+	return pdsConsts, nil
+}
+
+// WritePoseidonConstants writes the poseidon constants in json format, see ReadPoseidonConstants.
+func WritePoseidonConstants[E Element[E]](w io.Writer, pdsConsts *PoseidonConst[E]) error {
+	strs := constantsFile{
+		CompressedRoundConstants: elementToHex(pdsConsts.CompRoundConsts),
+		RoundConstants:           elementToHex(pdsConsts.RoundConsts),
+		Mds:                      matrixToHex(pdsConsts.Mds.m),
+		Sparse:                   make([][][]string, len(pdsConsts.Sparse)),
+		PreSparse:                matrixToHex(pdsConsts.PreSparse),
+	}
+
+	for i := 0; i < len(pdsConsts.Sparse); i++ {
+		strs.Sparse[i] = [][]string{elementToHex(pdsConsts.Sparse[i].WHat), elementToHex(pdsConsts.Sparse[i].V)}
+	}
+
+	if err := json.NewEncoder(w).Encode(&strs); err != nil {
+		return fmt.Errorf("encode constants err: %w", err)
+	}
+
+	return nil
+}
+
+// isConstantsEqual determines if two poseidon constants are equal.
+func isConstantsEqual[E Element[E]](a, b *PoseidonConst[E]) error {
+	switch {
+	case a.FullRounds != b.FullRounds || a.PartialRounds != b.PartialRounds || a.HalfFullRounds != b.HalfFullRounds:
+		return errors.New("round numbers are not equal")
+	case !IsVecEqual(a.RoundConsts, b.RoundConsts):
+		return errors.New("round constants are not equal")
+	case !IsVecEqual(a.CompRoundConsts, b.CompRoundConsts):
+		return errors.New("compressed round constants are not equal")
+	case !IsEqual(a.Mds.m, b.Mds.m):
+		return errors.New("mds matrices are not equal")
+	case !IsEqual(a.PreSparse, b.PreSparse):
+		return errors.New("pre-sparse matrices are not equal")
+	case len(a.Sparse) != len(b.Sparse):
+		return errors.New("sparse matrices are not equal")
+	}
+
+	for i := 0; i < len(a.Sparse); i++ {
+		if !IsVecEqual(a.Sparse[i].WHat, b.Sparse[i].WHat) || !IsVecEqual(a.Sparse[i].V, b.Sparse[i].V) {
+			return fmt.Errorf("sparse matrix %d is not equal", i)
+		}
+	}
+
+	return nil
+}
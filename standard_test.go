@@ -0,0 +1,88 @@
+package poseidon
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadStandardConstants(t *testing.T) {
+	for _, width := range []int{3, 4, 5, 12} {
+		cons, err := LoadStandardConstants[*fr.Element](width)
+		assert.NoError(t, err)
+		assert.NoError(t, cons.Verify())
+
+		gen, err := GenPoseidonConstants[*fr.Element](width)
+		assert.NoError(t, err)
+		assert.NoError(t, isConstantsEqual(cons, gen))
+	}
+
+	cons, err := LoadStandardConstants[*bn254fr.Element](3)
+	assert.NoError(t, err)
+	assert.NoError(t, cons.Verify())
+
+	// write and read the constants.
+	var buf bytes.Buffer
+	assert.NoError(t, WritePoseidonConstants(&buf, cons))
+	get, err := ReadPoseidonConstants[*bn254fr.Element](&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, cons, get)
+}
+
+func TestReadPoseidonConstants(t *testing.T) {
+	cons, err := LoadStandardConstants[*bn254fr.Element](3)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		modify func(f *constantsFile)
+		want   string
+	}{
+		{func(f *constantsFile) { f.CompressedRoundConstants = f.CompressedRoundConstants[1:] }, "compressed round constants length"},
+		{func(f *constantsFile) { f.Mds[1] = f.Mds[1][1:] }, "mds matrix"},
+		{func(f *constantsFile) { f.PreSparse = f.PreSparse[1:] }, "pre-sparse matrix"},
+		{func(f *constantsFile) { f.PreSparse[2] = f.PreSparse[2][1:] }, "pre-sparse matrix"},
+		{func(f *constantsFile) { f.Sparse[7][1] = f.Sparse[7][1][1:] }, "sparse matrix 7"},
+		{func(f *constantsFile) { f.Sparse[7][0] = append(f.Sparse[7][0], "1") }, "sparse matrix 7"},
+		{func(f *constantsFile) { f.CompressedRoundConstants[5] = "1" }, "compressed round constants are inconsistent"},
+		{func(f *constantsFile) { f.PreSparse[1][1] = "1" }, "verify constants"},
+	}
+
+	for _, cases := range tests {
+		var buf bytes.Buffer
+		assert.NoError(t, WritePoseidonConstants(&buf, cons))
+
+		var f constantsFile
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &f))
+		cases.modify(&f)
+
+		b, err := json.Marshal(&f)
+		assert.NoError(t, err)
+		_, err = ReadPoseidonConstants[*bn254fr.Element](bytes.NewReader(b))
+		assert.ErrorContains(t, err, cases.want)
+	}
+}
+
+func TestVerifyStandardConstants(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the generation of the standard constants in short mode")
+	}
+
+	assert.NoError(t, VerifyStandardConstants[*fr.Element]())
+	assert.NoError(t, VerifyStandardConstants[*bn254fr.Element]())
+}
+
+func BenchmarkLoadStandardConstants(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = LoadStandardConstants[*fr.Element](37)
+	}
+}
+
+func BenchmarkGenPoseidonConstants(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = GenPoseidonConstants[*fr.Element](37)
+	}
+}
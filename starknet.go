@@ -0,0 +1,128 @@
+package poseidon
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+
+	starkfp "github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+)
+
+// the parameters of starknet's poseidon (hades) over the Stark252 field, p = 2^251 + 17*2^192 + 1.
+const (
+	starknetWidth         = 3
+	starknetFullRounds    = 8
+	starknetPartialRounds = 83
+)
+
+var (
+	starknetOnce sync.Once
+	starknetPerm *SPN[*starkfp.Element]
+)
+
+// starknetSPN returns the hades permutation of cairo-lang (poseidon_utils.py), which is generated on the first call.
+// each round adds the constants, applies x^3 to all elements (full rounds) or to the last element (partial rounds),
+// and multiplies the state by the matrix [[3, 1, 1], [1, -1, 1], [1, 1, -2]] (M*x).
+func starknetSPN() *SPN[*starkfp.Element] {
+	starknetOnce.Do(func() {
+		alpha := big.NewInt(3)
+		schedule := HadesSchedule{FullRounds: starknetFullRounds, PartialRounds: starknetPartialRounds}
+
+		starknetPerm = &SPN[*starkfp.Element]{
+			Schedule: schedule,
+			SBox: SBoxLayerFunc[*starkfp.Element](func(state []*starkfp.Element, _ int, kind RoundKind, tmp *starkfp.Element) {
+				if kind == PartialRound {
+					state = state[len(state)-1:]
+				}
+
+				sboxLayer(state, alpha, tmp)
+			}),
+			Linear:    LinearLayerFunc[*starkfp.Element](starknetMix),
+			PreConsts: genStarknetRoundConstants(schedule.NumRounds()),
+		}
+	})
+
+	return starknetPerm
+}
+
+// genStarknetRoundConstants generates the round constants, the j-th constant of the round i is
+// sha256("Hades" || decimal(3*i+j)) mod p.
+func genStarknetRoundConstants(rounds int) [][]*starkfp.Element {
+	constants := make([][]*starkfp.Element, rounds)
+	for i := 0; i < rounds; i++ {
+		constants[i] = make([]*starkfp.Element, starknetWidth)
+		for j := 0; j < starknetWidth; j++ {
+			digest := sha256.Sum256([]byte("Hades" + strconv.Itoa(starknetWidth*i+j)))
+			constants[i][j] = new(starkfp.Element).SetBytes(digest[:])
+		}
+	}
+
+	return constants
+}
+
+// starknetMix computes M*x without multiplications, where t = x0 + x1 + x2 and
+// M*x = (t + 2*x0, t - 2*x1, t - 3*x2).
+func starknetMix(dst, state []*starkfp.Element, _ int, tmp *starkfp.Element) {
+	tmp.Add(state[0], state[1])
+	tmp.Add(tmp, state[2])
+
+	dst[0].Add(tmp, state[0])
+	dst[0].Add(dst[0], state[0])
+
+	dst[1].Sub(tmp, state[1])
+	dst[1].Sub(dst[1], state[1])
+
+	dst[2].Sub(tmp, state[2])
+	dst[2].Sub(dst[2], state[2])
+	dst[2].Sub(dst[2], state[2])
+}
+
+// StarknetPermute computes starknet's hades_permutation of the 3 elements.
+func StarknetPermute(input []*big.Int) ([]*big.Int, error) {
+	if len(input) != starknetWidth {
+		return nil, fmt.Errorf("input length %d should be %d", len(input), starknetWidth)
+	}
+
+	state, err := canonicalToElement[*starkfp.Element](input)
+	if err != nil {
+		return nil, err
+	}
+
+	return elementToBig(starknetSPN().Permute(state, nil)), nil
+}
+
+// StarknetHash computes starknet's poseidon_hash(x, y), that is, hades(x, y, 2)[0].
+func StarknetHash(x, y *big.Int) (*big.Int, error) {
+	out, err := StarknetPermute([]*big.Int{x, y, big.NewInt(2)})
+	if err != nil {
+		return nil, err
+	}
+
+	return out[0], nil
+}
+
+// StarknetHashMany computes starknet's poseidon_hash_many, the values are padded with 1 and then
+// with 0 to an even length, and absorbed 2 at a time into the zero state, the output is state[0].
+func StarknetHashMany(values []*big.Int) (*big.Int, error) {
+	elems, err := canonicalToElement[*starkfp.Element](values)
+	if err != nil {
+		return nil, err
+	}
+
+	elems = append(elems, new(starkfp.Element).SetOne())
+	if len(elems)%2 != 0 {
+		elems = append(elems, new(starkfp.Element))
+	}
+
+	perm := starknetSPN()
+	state := newElements[*starkfp.Element](starknetWidth)
+	for i := 0; i < len(elems); i += 2 {
+		state[0].Add(state[0], elems[i])
+		state[1].Add(state[1], elems[i+1])
+		perm.Permute(state, nil)
+	}
+
+	return state[0].BigInt(new(big.Int)), nil
+}
@@ -0,0 +1,65 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	starkfp "github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+	"github.com/stretchr/testify/assert"
+)
+
+// felt parses the hex-string of a felt.
+func felt(s string) *big.Int {
+	return hexToBig([]string{s})[0]
+}
+
+func TestStarknetConstants(t *testing.T) {
+	constants := starknetSPN().PreConsts
+	assert.Equal(t, starknetFullRounds+starknetPartialRounds, len(constants))
+	// the first and the last round constants of cairo-lang.
+	assert.Equal(t, felt("6861759ea556a2339dd92f9562a30b9e58e2ad98109ae4780b7fd8eac77fe6f"), constants[0][0].BigInt(new(big.Int)))
+	assert.Equal(t, felt("61fc552b8eb75e17ad0fb7aaa4ca528f415e14f0d9cdbed861a8db0bfff0c5b"), constants[90][2].BigInt(new(big.Int)))
+}
+
+func TestStarknetPoseidon(t *testing.T) {
+	x := felt("b662f9017fa7956fd70e26129b1833e10ad000fd37b4d9f4e0ce6884b7bbe")
+	y := felt("1fe356bf76102cdae1bfbdc173602ead228b12904c00dad9cf16e035468bea")
+
+	// the test vector of cairo-lang.
+	h, err := StarknetHash(x, y)
+	assert.NoError(t, err)
+	assert.Equal(t, felt("75540825a6ecc5dc7d7c2f5f868164182742227f1367d66c43ee51ec7937a81"), h)
+
+	out, err := StarknetPermute([]*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0)})
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{
+		felt("79e8d1e78258000a28fc9d49e233bc6852357968577b1e386550ed6a9086133"),
+		felt("3840d003d0f3f96dbb796ff6aa6a63be5b5404b91ccaabca256154cbb6fb984"),
+		felt("1eb39da3f7d3b04142d0ac83d9da00c9325a61fb2ef326e50b70eaa8a3c7cc7"),
+	}, out)
+
+	tests := []struct {
+		values []*big.Int
+		hash   string
+	}{
+		{nil, "2272be0f580fd156823304800919530eaa97430e972d7213ee13f4fbf7a5dbc"},
+		{[]*big.Int{x}, "46583159a2327fea16a2b798d513b8ecfbf0fbf0f45d1948a6c74fb6b0fb49b"},
+		{[]*big.Int{big.NewInt(1), big.NewInt(2)}, "371cb6995ea5e7effcd2e174de264b5b407027a75a231a70c2c8d196107f0e7"},
+		{[]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}, "2f0d8840bcf3bc629598d8a6cc80cb7c0d9e52d93dab244bbf9cd0dca0ad082"},
+		{[]*big.Int{x, y}, "2410cb746ec9c95631fc76f1afa650eed9fa2efc20632a42cdbe7d701ed0098"},
+	}
+
+	for _, c := range tests {
+		h, err := StarknetHashMany(c.values)
+		assert.NoError(t, err)
+		assert.Equal(t, felt(c.hash), h)
+	}
+
+	// the inputs should be in the field.
+	_, err = StarknetHash(Modulus[*starkfp.Element](), y)
+	assert.Error(t, err)
+	_, err = StarknetHashMany([]*big.Int{big.NewInt(-1)})
+	assert.Error(t, err)
+	_, err = StarknetPermute([]*big.Int{x, y})
+	assert.Error(t, err)
+}
@@ -1,7 +1,10 @@
 package poseidon
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 )
 
 // hexToElement converts hex-strings to finite field elements
@@ -32,6 +35,17 @@ func bigToElement[E Element[E]](big []*big.Int) []E {
 	return elementArray
 }
 
+// elementToBig converts finite field elements to big integers
+func elementToBig[E Element[E]](e []E) []*big.Int {
+	bigArray := make([]*big.Int, len(e))
+
+	for i := 0; i < len(e); i++ {
+		bigArray[i] = e[i].BigInt(new(big.Int))
+	}
+
+	return bigArray
+}
+
 // hexToBig converts hex-strings to big  integers
 func hexToBig(hex []string) []*big.Int {
 	bigArray := make([]*big.Int, len(hex))
@@ -42,3 +56,129 @@ func hexToBig(hex []string) []*big.Int {
 
 	return bigArray
 }
+
+// hexToElementErr converts hex-strings (with or without the 0x prefix) to finite field elements,
+// it returns an error instead of panicking.
+func hexToElementErr[E Element[E]](hex []string) ([]E, error) {
+	b, err := hexToBigErr(hex)
+	if err != nil {
+		return nil, err
+	}
+
+	return bigToElement[E](b), nil
+}
+
+// hexToBigErr converts hex-strings (with or without the 0x prefix) to big integers.
+func hexToBigErr(hex []string) ([]*big.Int, error) {
+	bigArray := make([]*big.Int, len(hex))
+
+	for i := 0; i < len(hex); i++ {
+		var ok bool
+		bigArray[i], ok = new(big.Int).SetString(strings.TrimPrefix(hex[i], "0x"), 16)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse %q into a big.Int", hex[i])
+		}
+	}
+
+	return bigArray, nil
+}
+
+// canonicalToElement converts the values to finite field elements, the values should be in [0, p),
+// which is required by the implementations that reject the values not in the field (e.g. circomlib).
+func canonicalToElement[E Element[E]](values []*big.Int) ([]E, error) {
+	for _, x := range values {
+		if x.Sign() < 0 || !IsValid[E](x) {
+			return nil, errors.New("the input is not in the field")
+		}
+	}
+
+	return bigToElement[E](values), nil
+}
+
+// decimalToElement converts decimal strings (or with a base prefix, e.g. 0x) to finite field elements,
+// the values should be in the field.
+func decimalToElement[E Element[E]](strs []string) ([]E, error) {
+	elements := make([]E, len(strs))
+
+	for i := 0; i < len(strs); i++ {
+		b, ok := new(big.Int).SetString(strs[i], 0)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse %q into a big.Int", strs[i])
+		}
+
+		if b.Sign() < 0 || !IsValid[E](b) {
+			return nil, fmt.Errorf("%q is not in the field", strs[i])
+		}
+
+		elements[i] = NewElement[E]().SetBigInt(b)
+	}
+
+	return elements, nil
+}
+
+// decimalToMatrix converts a matrix of decimal strings to a matrix of finite field elements, see decimalToElement.
+func decimalToMatrix[E Element[E]](strs [][]string) (Matrix[E], error) {
+	m := make([][]E, len(strs))
+
+	var err error
+	for i := 0; i < len(strs); i++ {
+		m[i], err = decimalToElement[E](strs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// elementToHex converts finite field elements to hex-strings, which are padded to the field size.
+func elementToHex[E Element[E]](e []E) []string {
+	hex := make([]string, len(e))
+
+	for i := 0; i < len(e); i++ {
+		hex[i] = fmt.Sprintf("%0*x", 2*Bytes[E](), e[i].BigInt(new(big.Int)))
+	}
+
+	return hex
+}
+
+// hexToMatrix converts a matrix of hex-strings to a matrix of finite field elements.
+func hexToMatrix[E Element[E]](hex [][]string) (Matrix[E], error) {
+	m := make([][]E, len(hex))
+
+	var err error
+	for i := 0; i < len(hex); i++ {
+		m[i], err = hexToElementErr[E](hex[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// matrixToHex converts a matrix of finite field elements to a matrix of hex-strings.
+func matrixToHex[E Element[E]](m Matrix[E]) [][]string {
+	hex := make([][]string, len(m))
+
+	for i := 0; i < len(m); i++ {
+		hex[i] = elementToHex(m[i])
+	}
+
+	return hex
+}
+
+// isBigEqual determines if two slices of big integers are equal.
+func isBigEqual(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := 0; i < len(a); i++ {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
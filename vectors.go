@@ -0,0 +1,454 @@
+package poseidon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// the kinds of the test vectors.
+const (
+	// HashVector is checked against Hash, the domain tag is added to the input,
+	// and the output is the hash value.
+	HashVector = "hash"
+	// PermutationVector is checked against Permute, the input is the whole state,
+	// and the output is the whole permuted state.
+	PermutationVector = "permutation"
+)
+
+// ErrFieldMismatch is returned if the test vector is defined over another field.
+var ErrFieldMismatch = errors.New("test vector field mismatch")
+
+// TestVectorFile is a set of known-answer test vectors,
+// it is used to exchange test vectors with other implementations.
+type TestVectorFile struct {
+	// Source describes the implementation which generated the test vectors.
+	Source  string            `json:"source"`
+	Vectors []*TestVectorCase `json:"vectors"`
+}
+
+// TestVectorParams describes the poseidon instance of the test vector.
+// all field elements are encoded as hex-strings, with or without the 0x prefix.
+type TestVectorParams struct {
+	// Modulus is the prime of the field.
+	Modulus       string `json:"modulus"`
+	Width         int    `json:"width"`
+	FullRounds    int    `json:"full_rounds"`
+	PartialRounds int    `json:"partial_rounds"`
+	Alpha         int    `json:"alpha"`
+	// RoundConstants and Mds are optional, if they are omitted,
+	// the constants are generated by GenPoseidonConstants.
+	RoundConstants []string   `json:"round_constants,omitempty"`
+	Mds            [][]string `json:"mds,omitempty"`
+	// DomainTag is the first element of the state in the hash test vectors,
+	// the domain tag 0x3 of Hash is used if it is omitted, e.g. circomlib uses 0.
+	DomainTag string `json:"domain_tag,omitempty"`
+	// OutputIndex is the index of the hash output in the permuted state,
+	// the output state[1] of Hash is used if it is omitted, e.g. circomlib outputs state[0].
+	OutputIndex *int `json:"output_index,omitempty"`
+}
+
+// hashDomainTag is the domain tag used in Hash.
+const hashDomainTag = 3
+
+// hashOutputIndex is the index of the output of Hash in the permuted state.
+const hashOutputIndex = 1
+
+// domainTag returns the domain tag of the hash test vectors.
+func (p *TestVectorParams) domainTag() (*big.Int, error) {
+	if p.DomainTag == "" {
+		return big.NewInt(hashDomainTag), nil
+	}
+
+	tag, err := hexToBigErr([]string{p.DomainTag})
+	if err != nil {
+		return nil, fmt.Errorf("parse domain tag err: %w", err)
+	}
+
+	return tag[0], nil
+}
+
+// outputIndex returns the index of the hash output in the permuted state.
+func (p *TestVectorParams) outputIndex() int {
+	if p.OutputIndex == nil {
+		return hashOutputIndex
+	}
+
+	return *p.OutputIndex
+}
+
+// TestVectorCase is a known-answer test vector.
+type TestVectorCase struct {
+	Name   string           `json:"name"`
+	Kind   string           `json:"kind"`
+	Params TestVectorParams `json:"params"`
+	Input  []string         `json:"input"`
+	Output []string         `json:"output"`
+	// RoundStates are the optional intermediate states after each round.
+	RoundStates [][]string `json:"round_states,omitempty"`
+}
+
+// ReadTestVectors reads the test vectors in json format.
+func ReadTestVectors(r io.Reader) (*TestVectorFile, error) {
+	f := new(TestVectorFile)
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return nil, fmt.Errorf("decode test vectors err: %w", err)
+	}
+
+	return f, nil
+}
+
+// WriteTestVectors writes the test vectors in json format.
+func WriteTestVectors(w io.Writer, f *TestVectorFile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return fmt.Errorf("encode test vectors err: %w", err)
+	}
+
+	return nil
+}
+
+// GenTestVector generates a test vector of the given kind by this library,
+// if withConstants is true, the round constants and the mds matrix are embedded in the test vector.
+func GenTestVector[E Element[E]](name, kind string, input []*big.Int, pdsConsts *PoseidonConst[E], withConstants bool) (*TestVectorCase, error) {
+	width := row(pdsConsts.Mds.m)
+	v := &TestVectorCase{
+		Name: name,
+		Kind: kind,
+		Params: TestVectorParams{
+			Modulus:       Modulus[E]().Text(16),
+			Width:         width,
+			FullRounds:    pdsConsts.FullRounds,
+			PartialRounds: pdsConsts.PartialRounds,
+			Alpha:         int(pdsConsts.alpha().Int64()),
+		},
+		Input: elementToHex(bigToElement[E](input)),
+	}
+
+	if withConstants {
+		v.Params.RoundConstants = elementToHex(pdsConsts.RoundConsts)
+		v.Params.Mds = matrixToHex(pdsConsts.Mds.m)
+	}
+
+	state, err := vectorState[E](kind, &v.Params, input)
+	if err != nil {
+		return nil, err
+	}
+
+	state = permute(state, pdsConsts, func(s []E) {
+		v.RoundStates = append(v.RoundStates, elementToHex(s))
+	})
+
+	switch kind {
+	case HashVector:
+		h, err := Hash(input, pdsConsts, OptimizedStatic)
+		if err != nil {
+			return nil, fmt.Errorf("hash err: %w", err)
+		}
+		v.Output = elementToHex(bigToElement[E]([]*big.Int{h}))
+	case PermutationVector:
+		v.Output = elementToHex(state)
+	}
+
+	return v, nil
+}
+
+// CheckTestVector checks the test vector against Hash (in all hash modes) or Permute,
+// ErrFieldMismatch is returned if the test vector is defined over another field.
+func CheckTestVector[E Element[E]](v *TestVectorCase) error {
+	if err := checkModulus[E](); err != nil {
+		return err
+	}
+
+	pdsConsts, err := vectorConstants[E](v, nil)
+	if err != nil {
+		return err
+	}
+
+	return checkTestVector(v, pdsConsts)
+}
+
+func checkTestVector[E Element[E]](v *TestVectorCase, pdsConsts *PoseidonConst[E]) error {
+	input, err := hexToBigErr(v.Input)
+	if err != nil {
+		return fmt.Errorf("parse input err: %w", err)
+	}
+
+	output, err := hexToBigErr(v.Output)
+	if err != nil {
+		return fmt.Errorf("parse output err: %w", err)
+	}
+
+	switch v.Kind {
+	case HashVector:
+		if len(output) != 1 {
+			return fmt.Errorf("hash output length %d should be 1", len(output))
+		}
+
+		tag, err := v.Params.domainTag()
+		if err != nil {
+			return err
+		}
+
+		index := v.Params.outputIndex()
+		if index < 0 || index >= row(pdsConsts.Mds.m) {
+			return fmt.Errorf("output index %d is out of the state", index)
+		}
+
+		// Hash only supports its own domain tag and output, the others are checked against the permutation.
+		if tag.Cmp(big.NewInt(hashDomainTag)) != 0 || index != hashOutputIndex {
+			state, err := vectorState[E](v.Kind, &v.Params, input)
+			if err != nil {
+				return err
+			}
+
+			h := permute(state, pdsConsts, nil)[index].BigInt(new(big.Int))
+			if h.Cmp(output[0]) != 0 {
+				return fmt.Errorf("permutation output %d mismatch: got %x, want %x", index, h, output[0])
+			}
+			break
+		}
+
+		for _, mode := range []HashMode{OptimizedStatic, OptimizedDynamic, Correct} {
+			h, err := Hash(input, pdsConsts, mode)
+			if err != nil {
+				return fmt.Errorf("hash mode %d err: %w", mode, err)
+			}
+
+			if h.Cmp(output[0]) != 0 {
+				return fmt.Errorf("hash mode %d output mismatch: got %x, want %x", mode, h, output[0])
+			}
+		}
+	case PermutationVector:
+		get, err := Permute(input, pdsConsts)
+		if err != nil {
+			return fmt.Errorf("permute err: %w", err)
+		}
+
+		if len(get) != len(output) {
+			return fmt.Errorf("permutation output length %d should be %d", len(output), len(get))
+		}
+
+		for i := 0; i < len(get); i++ {
+			if get[i].Cmp(output[i]) != 0 {
+				return fmt.Errorf("permutation output %d mismatch: got %x, want %x", i, get[i], output[i])
+			}
+		}
+	default:
+		return fmt.Errorf("unknown test vector kind %q", v.Kind)
+	}
+
+	if len(v.RoundStates) == 0 {
+		return nil
+	}
+
+	// intermediate round states.
+	if len(v.RoundStates) != pdsConsts.FullRounds+pdsConsts.PartialRounds {
+		return fmt.Errorf("round states length %d should be %d", len(v.RoundStates), pdsConsts.FullRounds+pdsConsts.PartialRounds)
+	}
+
+	state, err := vectorState[E](v.Kind, &v.Params, input)
+	if err != nil {
+		return err
+	}
+
+	round := 0
+	err = nil
+	permute(state, pdsConsts, func(s []E) {
+		if err == nil {
+			want, e := hexToBigErr(v.RoundStates[round])
+			if e != nil {
+				err = fmt.Errorf("parse round %d state err: %w", round, e)
+			} else if !isBigEqual(elementToBig(s), want) {
+				err = fmt.Errorf("round %d state mismatch", round)
+			}
+		}
+		round++
+	})
+
+	return err
+}
+
+// vectorState returns the initial state of the permutation.
+func vectorState[E Element[E]](kind string, p *TestVectorParams, input []*big.Int) ([]E, error) {
+	state := bigToElement[E](input)
+	if kind == HashVector {
+		tag, err := p.domainTag()
+		if err != nil {
+			return nil, err
+		}
+		state = append([]E{NewElement[E]().SetBigInt(tag)}, state...)
+	} else if kind != PermutationVector {
+		return nil, fmt.Errorf("unknown test vector kind %q", kind)
+	}
+
+	if len(state) != p.Width {
+		return nil, fmt.Errorf("state length %d is inconsistent with the width %d", len(state), p.Width)
+	}
+
+	return state, nil
+}
+
+// vectorConstants returns the poseidon constants described in the test vector parameters,
+// the generated constants are cached by the width if the cache is not nil.
+func vectorConstants[E Element[E]](v *TestVectorCase, cache map[int]*PoseidonConst[E]) (*PoseidonConst[E], error) {
+	p := v.Params
+	modulus, ok := new(big.Int).SetString(strings.TrimPrefix(p.Modulus, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("cannot parse the modulus %q", p.Modulus)
+	}
+
+	if modulus.Cmp(Modulus[E]()) != 0 {
+		return nil, ErrFieldMismatch
+	}
+
+	// the constants of the test vectors use the s-box of the field.
+	if alpha := sboxAlpha[E](); p.Alpha != alpha {
+		return nil, fmt.Errorf("alpha %d is not supported, want %d", p.Alpha, alpha)
+	}
+
+	if len(p.RoundConstants) == 0 {
+		pdsConsts, ok := cache[p.Width]
+		if !ok {
+			var err error
+			pdsConsts, err = GenPoseidonConstants[E](p.Width)
+			if err != nil {
+				return nil, fmt.Errorf("generate poseidon constants err: %w", err)
+			}
+
+			if cache != nil {
+				cache[p.Width] = pdsConsts
+			}
+		}
+
+		if pdsConsts.FullRounds != p.FullRounds || pdsConsts.PartialRounds != p.PartialRounds {
+			return nil, fmt.Errorf("round numbers (%d, %d) are inconsistent with the generated constants (%d, %d)",
+				p.FullRounds, p.PartialRounds, pdsConsts.FullRounds, pdsConsts.PartialRounds)
+		}
+
+		return pdsConsts, nil
+	}
+
+	constants, err := hexToElementErr[E](p.RoundConstants)
+	if err != nil {
+		return nil, fmt.Errorf("parse round constants err: %w", err)
+	}
+
+	mds, err := hexToMatrix[E](p.Mds)
+	if err != nil {
+		return nil, fmt.Errorf("parse mds matrix err: %w", err)
+	}
+
+	return newPoseidonConstants(p.Width, p.FullRounds, p.PartialRounds, constants, mds)
+}
+
+// VectorResult is the result of a single test vector.
+type VectorResult struct {
+	File string
+	Name string
+	// Skipped is true if the test vector is defined over another field.
+	Skipped bool
+	Err     error
+}
+
+// ConformanceReport is the pass/fail report of the test vectors.
+type ConformanceReport struct {
+	Results []VectorResult
+}
+
+// Passed returns the number of passed test vectors.
+func (r *ConformanceReport) Passed() int {
+	n := 0
+	for _, res := range r.Results {
+		if !res.Skipped && res.Err == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Failed returns the number of failed test vectors.
+func (r *ConformanceReport) Failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err != nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r *ConformanceReport) String() string {
+	var b strings.Builder
+	skipped := 0
+	for _, res := range r.Results {
+		switch {
+		case res.Skipped:
+			skipped++
+			fmt.Fprintf(&b, "SKIP %s: %s\n", res.File, res.Name)
+		case res.Err != nil:
+			fmt.Fprintf(&b, "FAIL %s: %s: %v\n", res.File, res.Name, res.Err)
+		default:
+			fmt.Fprintf(&b, "PASS %s: %s\n", res.File, res.Name)
+		}
+	}
+	fmt.Fprintf(&b, "passed: %d, failed: %d, skipped: %d\n", r.Passed(), r.Failed(), skipped)
+
+	return b.String()
+}
+
+// RunTestVectors checks all test vector files (*.json) in the directory,
+// the test vectors defined over other fields are skipped.
+func RunTestVectors[E Element[E]](dir string) (*ConformanceReport, error) {
+	if err := checkModulus[E](); err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list test vector files err: %w", err)
+	}
+	sort.Strings(files)
+
+	// the generated constants are cached by the width.
+	cache := make(map[int]*PoseidonConst[E])
+	report := new(ConformanceReport)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("open test vector file err: %w", err)
+		}
+
+		vectors, err := ReadTestVectors(f)
+		f.Close()
+		if err != nil {
+			report.Results = append(report.Results, VectorResult{File: filepath.Base(file), Err: err})
+			continue
+		}
+
+		for _, v := range vectors.Vectors {
+			res := VectorResult{File: filepath.Base(file), Name: v.Name}
+
+			pdsConsts, err := vectorConstants[E](v, cache)
+			if errors.Is(err, ErrFieldMismatch) {
+				res.Skipped = true
+			} else if err != nil {
+				res.Err = err
+			} else {
+				res.Err = checkTestVector(v, pdsConsts)
+			}
+			report.Results = append(report.Results, res)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,98 @@
+package poseidon
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTestVectors(t *testing.T) {
+	report, err := RunTestVectors[*fr.Element]("./data/vectors")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Failed(), report.String())
+	assert.Equal(t, 5, report.Passed(), report.String())
+
+	report, err = RunTestVectors[*bn254fr.Element]("./data/vectors")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Failed(), report.String())
+	assert.Equal(t, 12, report.Passed(), report.String())
+}
+
+func TestTestVector(t *testing.T) {
+	cons, err := GenPoseidonConstants[*fr.Element](3)
+	assert.NoError(t, err)
+
+	input := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	v, err := GenTestVector("permutation", PermutationVector, input, cons, true)
+	assert.NoError(t, err)
+	assert.Equal(t, cons.FullRounds+cons.PartialRounds, len(v.RoundStates))
+	assert.Equal(t, v.Output, v.RoundStates[len(v.RoundStates)-1])
+
+	// write and read the test vectors.
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTestVectors(&buf, &TestVectorFile{Source: "test", Vectors: []*TestVectorCase{v}}))
+	f, err := ReadTestVectors(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, v, f.Vectors[0])
+	assert.NoError(t, CheckTestVector[*fr.Element](f.Vectors[0]))
+
+	// the test vector is defined over another field.
+	assert.ErrorIs(t, CheckTestVector[*bn254fr.Element](v), ErrFieldMismatch)
+
+	// wrong intermediate state.
+	v.RoundStates[10][1] = "0x1"
+	assert.ErrorContains(t, CheckTestVector[*fr.Element](v), "round 10 state mismatch")
+
+	// wrong output.
+	v, err = GenTestVector("hash", HashVector, input[1:], cons, false)
+	assert.NoError(t, err)
+	assert.NoError(t, CheckTestVector[*fr.Element](v))
+	v.Output[0] = "0x1"
+	assert.ErrorContains(t, CheckTestVector[*fr.Element](v), "output mismatch")
+
+	// the domain tag and the output index of other implementations, e.g. circomlib.
+	index := 0
+	v.Params.DomainTag = "0"
+	v.Params.OutputIndex = &index
+	v.RoundStates = nil
+	state, err := Permute([]*big.Int{big.NewInt(0), input[1], input[2]}, cons)
+	assert.NoError(t, err)
+	v.Output = elementToHex(bigToElement[*fr.Element](state[:1]))
+	assert.NoError(t, CheckTestVector[*fr.Element](v))
+
+	index = 2
+	assert.ErrorContains(t, CheckTestVector[*fr.Element](v), "permutation output 2 mismatch")
+	index = 3
+	assert.ErrorContains(t, CheckTestVector[*fr.Element](v), "out of the state")
+}
+
+func TestTestVectorGoldilocks(t *testing.T) {
+	// goldilocks uses x^7, which is written into the test vectors and checked.
+	cons, err := GenPoseidonConstants[*goldilocks.Element](12)
+	assert.NoError(t, err)
+
+	input := make([]*big.Int, 12)
+	for i := 0; i < len(input); i++ {
+		input[i] = big.NewInt(int64(i))
+	}
+
+	for _, withConstants := range []bool{true, false} {
+		v, err := GenTestVector("permutation", PermutationVector, input, cons, withConstants)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, v.Params.Alpha)
+
+		var buf bytes.Buffer
+		assert.NoError(t, WriteTestVectors(&buf, &TestVectorFile{Source: "test", Vectors: []*TestVectorCase{v}}))
+		f, err := ReadTestVectors(&buf)
+		assert.NoError(t, err)
+		assert.NoError(t, CheckTestVector[*goldilocks.Element](f.Vectors[0]))
+
+		v.Params.Alpha = 5
+		assert.ErrorContains(t, CheckTestVector[*goldilocks.Element](v), "alpha 5 is not supported")
+	}
+}
@@ -0,0 +1,48 @@
+package poseidon
+
+import (
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// zkEVMCapacity is the number of the capacity elements of polygon zkevm's poseidon, which is also the output length.
+const zkEVMCapacity = plonky2Width - plonky2Rate
+
+// ZkEVMHash computes polygon zkevm's poseidon hash of 8 inputs and 4 capacity elements
+// (poseidon of zkevm-commonjs, and Hash of goldenposeidon), the state is (inputs, capacity) and the output is the first 4 elements,
+// zkevm uses the same permutation and constants as plonky2, see Plonky2Permute. the values are reduced modulo p.
+func ZkEVMHash(inputs [plonky2Rate]uint64, capacity [zkEVMCapacity]uint64) ([zkEVMCapacity]uint64, error) {
+	var state [plonky2Width]uint64
+	copy(state[:plonky2Rate], inputs[:])
+	copy(state[plonky2Rate:], capacity[:])
+
+	var h [zkEVMCapacity]uint64
+	out, err := Plonky2Permute(state)
+	if err != nil {
+		return h, err
+	}
+
+	copy(h[:], out[:zkEVMCapacity])
+	return h, nil
+}
+
+// ZkEVMHashElements is ZkEVMHash over the goldilocks elements.
+func ZkEVMHashElements(inputs [plonky2Rate]goldilocks.Element, capacity [zkEVMCapacity]goldilocks.Element) ([zkEVMCapacity]goldilocks.Element, error) {
+	state := make([]*goldilocks.Element, plonky2Width)
+	for i := 0; i < plonky2Rate; i++ {
+		state[i] = new(goldilocks.Element).Set(&inputs[i])
+	}
+	for i := 0; i < zkEVMCapacity; i++ {
+		state[plonky2Rate+i] = new(goldilocks.Element).Set(&capacity[i])
+	}
+
+	var h [zkEVMCapacity]goldilocks.Element
+	if err := plonky2Permute(state); err != nil {
+		return h, err
+	}
+
+	for i := 0; i < zkEVMCapacity; i++ {
+		h[i].Set(state[i])
+	}
+
+	return h, nil
+}
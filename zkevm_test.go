@@ -0,0 +1,58 @@
+package poseidon
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZkEVMHash(t *testing.T) {
+	const p = goldilocksModulus
+
+	fill8 := func(x uint64) [plonky2Rate]uint64 {
+		return [plonky2Rate]uint64{x, x, x, x, x, x, x, x}
+	}
+	fill4 := func(x uint64) [zkEVMCapacity]uint64 {
+		return [zkEVMCapacity]uint64{x, x, x, x}
+	}
+
+	// the test vectors of goldenposeidon, which are the vectors of zkevm-commonjs.
+	tests := []struct {
+		inputs   [plonky2Rate]uint64
+		capacity [zkEVMCapacity]uint64
+		want     [zkEVMCapacity]uint64
+	}{
+		{fill8(0), fill4(0), [4]uint64{4330397376401421145, 14124799381142128323, 8742572140681234676, 14345658006221440202}},
+		{fill8(1), fill4(1), [4]uint64{16428316519797902711, 13351830238340666928, 682362844289978626, 12150588177266359240}},
+		{fill8(p - 1), fill4(p - 1), [4]uint64{13691089994624172887, 15662102337790434313, 14940024623104903507, 10772674582659927682}},
+		{fill8(p), fill4(0), [4]uint64{4330397376401421145, 14124799381142128323, 8742572140681234676, 14345658006221440202}},
+		{
+			[plonky2Rate]uint64{923978, 235763497586, 9827635653498, 112870, 289273673480943876, 230295874986745876, 6254867324987, 2087},
+			fill4(0),
+			[4]uint64{1892171027578617759, 984732815927439256, 7866041765487844082, 8161503938059336191},
+		},
+	}
+
+	for _, c := range tests {
+		h, err := ZkEVMHash(c.inputs, c.capacity)
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, h)
+
+		// the same over the elements.
+		var inputs [plonky2Rate]goldilocks.Element
+		var capacity [zkEVMCapacity]goldilocks.Element
+		for i := 0; i < plonky2Rate; i++ {
+			inputs[i].SetUint64(c.inputs[i])
+		}
+		for i := 0; i < zkEVMCapacity; i++ {
+			capacity[i].SetUint64(c.capacity[i])
+		}
+
+		e, err := ZkEVMHashElements(inputs, capacity)
+		assert.NoError(t, err)
+		for i := 0; i < zkEVMCapacity; i++ {
+			assert.Equal(t, c.want[i], e[i].Uint64())
+		}
+	}
+}